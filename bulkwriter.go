@@ -0,0 +1,275 @@
+package mongoc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ErrWriterClosed is the error when write model is added to closed bulk writer.
+var ErrWriterClosed = fmt.Errorf("bulk writer is closed")
+
+//BulkWriter is the auto flushing bulk, it collects the write model to bulk and
+//flushes it to database when the model count/document bytes reach the limit or the interval is timeout.
+//
+//the flush is executed on pooled client concurrently, and Add will be blocked when all flush is running.
+//the options must be set before the first write model is added.
+//if Concurrency is greater than 1, the order between flushes is not guaranteed even if Ordered is true.
+//the Callback is called after the flush is done, it can call Add/Flush, but it must not call Close.
+type BulkWriter struct {
+	C           *Collection
+	Ordered     bool
+	Opts        *BulkOptions                      //the bulk level options, it can be nil.
+	MaxOps      int                               //flush when the model count reach it, default is 1000.
+	MaxBytes    int                               //flush when the document bytes reach it, default is 8MB.
+	Interval    time.Duration                     //flush when the interval is timeout, default is 1s.
+	Concurrency int                               //the max flush executed concurrently, default is 1.
+	Callback    func(reply *BulkReply, err error) //it will be called after each flush.
+	//
+	bulk      *Bulk
+	sources   []WriteModel //the added model of bulk, it is used as WriteError.Op.
+	bytes     int
+	callbacks sync.WaitGroup
+	lck       sync.Mutex
+	running   chan int
+	waiter    sync.WaitGroup
+	stop      chan int
+	started   bool
+	closed    bool
+}
+
+//NewBulkWriter will create the auto flushing bulk writer, the callback can be nil.
+//ordered:If the operations must be performed in order.
+func (c *Collection) NewBulkWriter(ordered bool, callback func(reply *BulkReply, err error)) *BulkWriter {
+	return &BulkWriter{
+		C:           c,
+		Ordered:     ordered,
+		MaxOps:      1000,
+		MaxBytes:    8 * 1024 * 1024,
+		Interval:    time.Second,
+		Concurrency: 1,
+		Callback:    callback,
+	}
+}
+
+//Add will add multi write model to writer, it will flush when the limit is reached.
+//the document in write model will be marshaled to []byte for counting the size, the model is not changed.
+//it return ErrWriterClosed when the writer is closed.
+func (w *BulkWriter) Add(models ...WriteModel) (err error) {
+	w.lck.Lock()
+	defer w.lck.Unlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if !w.started {
+		w.start()
+	}
+	for _, model := range models {
+		marshaled, size := marshalModel(model)
+		w.bytes += size
+		w.bulk.Cmds = append(w.bulk.Cmds, marshaled)
+		w.sources = append(w.sources, model)
+		if len(w.bulk.Cmds) >= w.MaxOps || w.bytes >= w.MaxBytes {
+			w.flush()
+		}
+	}
+	return
+}
+
+//start will apply the default options and start the interval loop, it must be called with lock.
+func (w *BulkWriter) start() {
+	if w.MaxOps < 1 {
+		w.MaxOps = 1000
+	}
+	if w.MaxBytes < 1 {
+		w.MaxBytes = 8 * 1024 * 1024
+	}
+	if w.Interval <= 0 {
+		w.Interval = time.Second
+	}
+	if w.Concurrency < 1 {
+		w.Concurrency = 1
+	}
+	w.started = true
+	w.bulk = w.C.NewBulkWithOpts(w.Ordered, w.Opts)
+	w.running = make(chan int, w.Concurrency)
+	w.stop = make(chan int)
+	go w.loop(w.Interval)
+}
+
+//Feed will add all write model from channel to writer until the channel is closed.
+//it return ErrWriterClosed when the writer is closed, and the remaining model is not consumed.
+func (w *BulkWriter) Feed(models <-chan WriteModel) (err error) {
+	for model := range models {
+		err = w.Add(model)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//Insert will add multi insert model to writer, see Bulk.Insert.
+func (w *BulkWriter) Insert(docs ...interface{}) error {
+	var bulk = &Bulk{}
+	bulk.Insert(docs...)
+	return w.Add(bulk.Cmds...)
+}
+
+//Remove will add remove model to writer, see Bulk.Remove.
+func (w *BulkWriter) Remove(selector interface{}) error {
+	var bulk = &Bulk{}
+	bulk.Remove(selector)
+	return w.Add(bulk.Cmds...)
+}
+
+//RemoveOne will add remove one model to writer, see Bulk.RemoveOne.
+func (w *BulkWriter) RemoveOne(selector interface{}) error {
+	var bulk = &Bulk{}
+	bulk.RemoveOne(selector)
+	return w.Add(bulk.Cmds...)
+}
+
+//Replace will add replace model to writer, see Bulk.Replace.
+func (w *BulkWriter) Replace(selector, document interface{}, upsert bool) error {
+	var bulk = &Bulk{}
+	bulk.Replace(selector, document, upsert)
+	return w.Add(bulk.Cmds...)
+}
+
+//Update will add update model to writer, see Bulk.Update.
+func (w *BulkWriter) Update(selector, document interface{}, upsert bool) error {
+	var bulk = &Bulk{}
+	bulk.Update(selector, document, upsert)
+	return w.Add(bulk.Cmds...)
+}
+
+//UpdateOne will add update one model to writer, see Bulk.UpdateOne.
+func (w *BulkWriter) UpdateOne(selector, document interface{}, upsert bool) error {
+	var bulk = &Bulk{}
+	bulk.UpdateOne(selector, document, upsert)
+	return w.Add(bulk.Cmds...)
+}
+
+//Flush will flush all pending write model and wait all running flush done, the Callback may be running after it return.
+func (w *BulkWriter) Flush() {
+	w.lck.Lock()
+	if w.started && len(w.bulk.Cmds) > 0 {
+		w.flush()
+	}
+	w.lck.Unlock()
+	w.waiter.Wait()
+}
+
+//Close will flush all pending write model and stop the writer, it return after all Callback is done.
+func (w *BulkWriter) Close() {
+	w.lck.Lock()
+	if w.closed {
+		w.lck.Unlock()
+		return
+	}
+	w.closed = true
+	if w.started {
+		if len(w.bulk.Cmds) > 0 {
+			w.flush()
+		}
+		close(w.stop)
+	}
+	w.lck.Unlock()
+	w.waiter.Wait()
+	w.callbacks.Wait()
+}
+
+//loop will flush the pending write model by interval.
+func (w *BulkWriter) loop(interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.lck.Lock()
+			if len(w.bulk.Cmds) > 0 {
+				w.flush()
+			}
+			w.lck.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+//flush will execute the pending bulk on background, it must be called with lock.
+//the callback is called after the flush is marked as done, so it can call Flush without waiting itself.
+func (w *BulkWriter) flush() {
+	var bulk, sources = w.bulk, w.sources
+	w.bulk = w.C.NewBulkWithOpts(w.Ordered, w.Opts)
+	w.sources = nil
+	w.bytes = 0
+	w.running <- 1 //wait for idle.
+	w.waiter.Add(1)
+	w.callbacks.Add(1)
+	go func() {
+		defer w.callbacks.Done()
+		reply, err := bulk.Execute()
+		var errors WriteErrors
+		if reply != nil {
+			errors = reply.Errors
+		}
+		if berr, ok := err.(*BulkWriteError); ok {
+			errors = append(errors, berr.WriteErrors...)
+		}
+		for _, werr := range errors { //point to the model which is added by caller.
+			if werr.Index >= 0 && werr.Index < len(sources) {
+				werr.Op = sources[werr.Index]
+			}
+		}
+		<-w.running
+		w.waiter.Done()
+		if w.Callback != nil {
+			w.Callback(reply, err)
+		}
+	}()
+}
+
+//marshalModel will marshal the document in write model to []byte and return the copied model with bytes size,
+//the source model is not changed.
+func marshalModel(source WriteModel) (model WriteModel, size int) {
+	var docs []*interface{}
+	model = source
+	switch m := source.(type) {
+	case *InsertOneModel:
+		c := *m
+		model, docs = &c, []*interface{}{&c.Document}
+	case *UpdateOneModel:
+		c := *m
+		model, docs = &c, []*interface{}{&c.Filter, &c.Update}
+	case *UpdateManyModel:
+		c := *m
+		model, docs = &c, []*interface{}{&c.Filter, &c.Update}
+	case *ReplaceOneModel:
+		c := *m
+		model, docs = &c, []*interface{}{&c.Filter, &c.Replacement}
+	case *DeleteOneModel:
+		c := *m
+		model, docs = &c, []*interface{}{&c.Filter}
+	case *DeleteManyModel:
+		c := *m
+		model, docs = &c, []*interface{}{&c.Filter}
+	}
+	for _, doc := range docs {
+		switch v := (*doc).(type) {
+		case nil:
+		case []byte:
+			size += len(v)
+		default:
+			bys, err := bson.Marshal(v)
+			if err == nil { //the error will be returned on execute.
+				*doc = bys
+				size += len(bys)
+			}
+		}
+	}
+	return
+}
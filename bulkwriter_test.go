@@ -0,0 +1,146 @@
+package mongoc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestBulkWriter(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	var lck sync.Mutex
+	var flushed, inserted int
+	var errs []error
+	writer := col.NewBulkWriter(false, func(reply *BulkReply, err error) {
+		lck.Lock()
+		defer lck.Unlock()
+		flushed++
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		inserted += reply.Inserted
+	})
+	writer.MaxOps = 100
+	writer.Concurrency = 3
+	writer.Interval = 100 * time.Millisecond
+	//
+	//flush by ops count
+	for i := 0; i < 250; i++ {
+		writer.Insert(bson.M{"_id": fmt.Sprintf("writer-%v", i)})
+	}
+	writer.Flush()
+	writer.callbacks.Wait()
+	if flushed != 3 || inserted != 250 || len(errs) > 0 {
+		t.Errorf("flushed:%v,inserted:%v,errs:%v", flushed, inserted, errs)
+		return
+	}
+	//
+	//flush by interval
+	models := make(chan WriteModel)
+	go func() {
+		for i := 0; i < 10; i++ {
+			models <- &UpdateOneModel{
+				Filter: bson.M{"_id": fmt.Sprintf("writer-%v", i)},
+				Update: bson.M{"$set": bson.M{"a": 1}},
+			}
+		}
+		close(models)
+	}()
+	writer.Feed(models)
+	time.Sleep(300 * time.Millisecond)
+	lck.Lock()
+	if flushed != 4 {
+		t.Errorf("flushed:%v", flushed)
+		lck.Unlock()
+		return
+	}
+	lck.Unlock()
+	//
+	//flush by close with error.
+	duplicate := &InsertOneModel{Document: bson.M{"_id": "writer-0"}}
+	writer.Add(duplicate)
+	writer.Close()
+	writer.Close()
+	if flushed != 5 || len(errs) != 1 {
+		t.Errorf("flushed:%v,errs:%v", flushed, errs)
+		return
+	}
+	if berr, ok := errs[0].(*BulkWriteError); !ok || len(berr.WriteErrors) != 1 || berr.WriteErrors[0].Op != duplicate {
+		t.Errorf("errs:%v", errs)
+		return
+	}
+	count, err := col.CountDocuments(bson.M{"a": 1}, nil)
+	if err != nil || count != 10 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	//
+	//flush in callback
+	var reentry *BulkWriter
+	reentry = col.NewBulkWriter(false, func(reply *BulkReply, err error) {
+		reentry.Flush()
+	})
+	reentry.Insert(bson.M{"_id": "writer-reentry"})
+	reentry.Flush()
+	reentry.Close()
+}
+
+func TestMarshalModel(t *testing.T) {
+	model := &ReplaceOneModel{
+		Filter:      bson.M{"a": 1},
+		Replacement: []byte{1, 2, 3},
+		Opts:        &BulkUpdateOptions{Upsert: true},
+	}
+	marshaled, size := marshalModel(model)
+	if bys, ok := marshaled.(*ReplaceOneModel).Filter.([]byte); !ok || size != len(bys)+3 || !marshaled.(*ReplaceOneModel).Opts.Upsert {
+		t.Errorf("marshal error:%v,%v", size, marshaled)
+		return
+	}
+	if _, ok := model.Filter.(bson.M); !ok {
+		t.Errorf("source is changed:%v", model)
+		return
+	}
+	bulk := &Bulk{}
+	bulk.Insert(1, 2)
+	if bulk.Len() != 2 {
+		t.Error("len error")
+		return
+	}
+	bulk.Reset()
+	if bulk.Len() != 0 {
+		t.Error("reset error")
+		return
+	}
+}
+
+func TestBulkWriterDefault(t *testing.T) {
+	writer := &BulkWriter{C: &Collection{}}
+	err := writer.Insert(bson.M{"a": 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if writer.MaxOps != 1000 || writer.MaxBytes != 8*1024*1024 || writer.Interval != time.Second || writer.Concurrency != 1 || writer.bulk.Len() != 1 {
+		t.Errorf("default error:%v", writer)
+		return
+	}
+	writer.bulk.Reset()
+	writer.Close()
+	if err = writer.Insert(bson.M{"a": 1}); err != ErrWriterClosed {
+		t.Errorf("err:%v", err)
+		return
+	}
+	models := make(chan WriteModel, 1)
+	models <- &InsertOneModel{Document: bson.M{"a": 1}}
+	close(models)
+	if err = writer.Feed(models); err != ErrWriterClosed {
+		t.Errorf("err:%v", err)
+		return
+	}
+}
@@ -0,0 +1,85 @@
+package mongoc
+
+import (
+	"reflect"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//DiffUpdate will compare the old and new document and generate the minimal update document, following:
+//
+//	changed field to {$set:{a.b:new}}
+//	removed field to {$unset:{a.b:""}}
+//	appended array elements to {$push:{a.c:{$each:[new elements]}}}
+//
+//the nested document is compared by field, the array is replaced by $set when it is not appended only,
+//and the _id is never updated. it return empty bson.M when nothing is changed.
+//the old/new can be any value which can be marshaled to document, it return error when marshal fail.
+func DiffUpdate(old, new interface{}) (update bson.M, err error) {
+	oldDoc, err := toDoc(old)
+	if err != nil {
+		return
+	}
+	newDoc, err := toDoc(new)
+	if err != nil {
+		return
+	}
+	var set, unset, push = bson.M{}, bson.M{}, bson.M{}
+	diffDoc("", oldDoc, newDoc, set, unset, push)
+	delete(set, "_id")
+	delete(unset, "_id")
+	update = bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if len(push) > 0 {
+		update["$push"] = push
+	}
+	return
+}
+
+func toDoc(v interface{}) (doc bson.M, err error) {
+	doc = bson.M{}
+	if v == nil {
+		return
+	}
+	bys, err := bson.Marshal(v)
+	if err == nil {
+		err = bson.Unmarshal(bys, doc)
+	}
+	return
+}
+
+func diffDoc(prefix string, old, new bson.M, set, unset, push bson.M) {
+	for key, newVal := range new {
+		path := prefix + key
+		oldVal, ok := old[key]
+		if !ok {
+			set[path] = newVal
+			continue
+		}
+		oldDoc, oldIsDoc := oldVal.(bson.M)
+		newDoc, newIsDoc := newVal.(bson.M)
+		if oldIsDoc && newIsDoc {
+			diffDoc(path+".", oldDoc, newDoc, set, unset, push)
+			continue
+		}
+		oldArr, oldIsArr := oldVal.([]interface{})
+		newArr, newIsArr := newVal.([]interface{})
+		if oldIsArr && newIsArr && len(oldArr) > 0 && len(newArr) > len(oldArr) && reflect.DeepEqual(oldArr, newArr[:len(oldArr)]) {
+			push[path] = bson.M{"$each": newArr[len(oldArr):]}
+			continue
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			set[path] = newVal
+		}
+	}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			unset[prefix+key] = ""
+		}
+	}
+}
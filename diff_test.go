@@ -0,0 +1,76 @@
+package mongoc
+
+import (
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+type diffAddress struct {
+	City string `bson:"city"`
+	Zip  string `bson:"zip,omitempty"`
+}
+
+type diffUser struct {
+	ID      string       `bson:"_id"`
+	Name    string       `bson:"name"`
+	Age     int          `bson:"age"`
+	Tags    []string     `bson:"tags"`
+	Scores  []int        `bson:"scores"`
+	Address *diffAddress `bson:"address,omitempty"`
+	Note    string       `bson:"note,omitempty"`
+}
+
+func TestDiffUpdate(t *testing.T) {
+	old := &diffUser{
+		ID:      "a",
+		Name:    "x",
+		Age:     1,
+		Tags:    []string{"a", "b"},
+		Scores:  []int{1, 2},
+		Address: &diffAddress{City: "c1", Zip: "z1"},
+		Note:    "n",
+	}
+	new := &diffUser{
+		ID:      "b",
+		Name:    "x",
+		Age:     2,
+		Tags:    []string{"a", "b", "c"},
+		Scores:  []int{2, 1},
+		Address: &diffAddress{City: "c2"},
+	}
+	update, err := DiffUpdate(old, new)
+	if err != nil || fmt.Sprintf("%v", update) != "map[$push:map[tags:map[$each:[c]]] $set:map[address.city:c2 age:2 scores:[2 1]] $unset:map[address.zip: note:]]" {
+		t.Error(update)
+		return
+	}
+	//
+	//no change
+	update, err = DiffUpdate(old, old)
+	if err != nil || len(update) != 0 {
+		t.Error(update)
+		return
+	}
+	//
+	//nil and map
+	update, err = DiffUpdate(nil, bson.M{"a": bson.M{"b": 1}, "c": []interface{}{}})
+	if err != nil || fmt.Sprintf("%v", update) != "map[$set:map[a:map[b:1] c:[]]]" {
+		t.Error(update)
+		return
+	}
+	update, err = DiffUpdate(bson.M{"c": []int{}}, bson.M{"c": []int{1}})
+	if err != nil || fmt.Sprintf("%v", update) != "map[$set:map[c:[1]]]" {
+		t.Error(update)
+		return
+	}
+	//
+	//error
+	for _, args := range [][]interface{}{{1, old}, {old, TestDiffUpdate}} {
+		_, err = DiffUpdate(args[0], args[1])
+		if err == nil {
+			t.Errorf("%v not error", args)
+			return
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package mongoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ExplainOp is the operation to be explained, it is one of ExplainFind/ExplainAggregate/ExplainCount/ExplainDistinct/ExplainUpdate/ExplainDelete.
+type ExplainOp interface {
+	command(colname string) bson.D
+}
+
+//ExplainFind is the find operation to be explained.
+type ExplainFind struct {
+	Filter     interface{}
+	Projection interface{}
+	Sort       []string //the sort keys by ParseSorted.
+	Skip       int
+	Limit      int
+}
+
+func (e *ExplainFind) command(colname string) (cmd bson.D) {
+	cmd = bson.D{
+		{
+			Name:  "find",
+			Value: colname,
+		},
+		{
+			Name:  "filter",
+			Value: explainFilter(e.Filter),
+		},
+	}
+	if e.Projection != nil {
+		cmd = append(cmd, bson.DocElem{Name: "projection", Value: e.Projection})
+	}
+	if len(e.Sort) > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "sort", Value: ParseSorted(e.Sort...)})
+	}
+	if e.Skip > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "skip", Value: e.Skip})
+	}
+	if e.Limit > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "limit", Value: e.Limit})
+	}
+	return
+}
+
+//ExplainAggregate is the aggregate operation to be explained, the pipeline can be *Pipeline or stage array.
+type ExplainAggregate struct {
+	Pipeline interface{}
+}
+
+func (e *ExplainAggregate) command(colname string) bson.D {
+	return bson.D{
+		{
+			Name:  "aggregate",
+			Value: colname,
+		},
+		{
+			Name:  "pipeline",
+			Value: e.Pipeline,
+		},
+		{
+			Name:  "cursor",
+			Value: bson.M{},
+		},
+	}
+}
+
+//ExplainCount is the count operation to be explained.
+type ExplainCount struct {
+	Query interface{}
+}
+
+func (e *ExplainCount) command(colname string) bson.D {
+	return bson.D{
+		{
+			Name:  "count",
+			Value: colname,
+		},
+		{
+			Name:  "query",
+			Value: explainFilter(e.Query),
+		},
+	}
+}
+
+//ExplainDistinct is the distinct operation to be explained.
+type ExplainDistinct struct {
+	Key   string
+	Query interface{}
+}
+
+func (e *ExplainDistinct) command(colname string) bson.D {
+	return bson.D{
+		{
+			Name:  "distinct",
+			Value: colname,
+		},
+		{
+			Name:  "key",
+			Value: e.Key,
+		},
+		{
+			Name:  "query",
+			Value: explainFilter(e.Query),
+		},
+	}
+}
+
+//ExplainUpdate is the update operation to be explained, the update is not applied.
+type ExplainUpdate struct {
+	Selector interface{}
+	Update   interface{}
+	Upsert   bool
+	Multi    bool
+}
+
+func (e *ExplainUpdate) command(colname string) bson.D {
+	return bson.D{
+		{
+			Name:  "update",
+			Value: colname,
+		},
+		{
+			Name: "updates",
+			Value: []bson.M{
+				{
+					"q":      explainFilter(e.Selector),
+					"u":      e.Update,
+					"upsert": e.Upsert,
+					"multi":  e.Multi,
+				},
+			},
+		},
+	}
+}
+
+//ExplainDelete is the delete operation to be explained, the document is not deleted.
+type ExplainDelete struct {
+	Selector interface{}
+	Single   bool
+}
+
+func (e *ExplainDelete) command(colname string) bson.D {
+	var limit = 0
+	if e.Single {
+		limit = 1
+	}
+	return bson.D{
+		{
+			Name:  "delete",
+			Value: colname,
+		},
+		{
+			Name: "deletes",
+			Value: []bson.M{
+				{
+					"q":     explainFilter(e.Selector),
+					"limit": limit,
+				},
+			},
+		},
+	}
+}
+
+func explainFilter(filter interface{}) interface{} {
+	if filter == nil {
+		return bson.M{}
+	}
+	return filter
+}
+
+//ExplainResult is the parsed result of explain.
+type ExplainResult struct {
+	Stages        []string      //the winning plan stages from root to leaf, like [FETCH IXSCAN].
+	Indexes       []string      //the index names used by winning plan.
+	NReturned     int           //the count of returned document.
+	DocsExamined  int           //the count of examined document.
+	KeysExamined  int           //the count of examined index key.
+	ExecutionTime time.Duration //the execution time, it is zero when verbosity is queryPlanner.
+	Raw           bson.M        //the raw explain output.
+}
+
+//HasStage return true when the winning plan has the stage, like COLLSCAN/SORT.
+func (e *ExplainResult) HasStage(stage string) bool {
+	for _, having := range e.Stages {
+		if having == stage {
+			return true
+		}
+	}
+	return false
+}
+
+//Explain will explain the operation by verbosity and return the parsed result, following:
+//
+//	result, err := col.Explain(&mongoc.ExplainFind{Filter: bson.M{"a": 1}, Sort: []string{"-b"}}, "")
+//
+//the verbosity is queryPlanner/executionStats/allPlansExecution, default is executionStats.
+//for more https://docs.mongodb.com/manual/reference/command/explain/
+func (c *Collection) Explain(op ExplainOp, verbosity string) (result *ExplainResult, err error) {
+	if len(verbosity) < 1 {
+		verbosity = "executionStats"
+	}
+	return c.explain(op.command(c.Name), verbosity)
+}
+
+func (c *Collection) explain(cmd bson.D, verbosity string) (result *ExplainResult, err error) {
+	var client = c.Pool.Pop()
+	defer client.Close()
+	var raw = bson.M{}
+	err = client.Execute(c.DbName, bson.D{
+		{
+			Name:  "explain",
+			Value: cmd,
+		},
+		{
+			Name:  "verbosity",
+			Value: verbosity,
+		},
+	}, nil, &raw)
+	if err != nil {
+		return
+	}
+	result = parseExplain(raw)
+	return
+}
+
+//parseExplain will parse the explain output of find/aggregate/count/distinct/update/delete.
+func parseExplain(raw bson.M) (result *ExplainResult) {
+	result = &ExplainResult{Raw: raw}
+	planner, _ := raw["queryPlanner"].(bson.M)
+	stats, _ := raw["executionStats"].(bson.M)
+	if stages, ok := raw["stages"].([]interface{}); ok && planner == nil && len(stages) > 0 { //aggregate with multi stages.
+		if first, ok := stages[0].(bson.M); ok {
+			cursor, _ := first["$cursor"].(bson.M)
+			planner, _ = cursor["queryPlanner"].(bson.M)
+			stats, _ = cursor["executionStats"].(bson.M)
+		}
+	}
+	if planner != nil {
+		plan, _ := planner["winningPlan"].(bson.M)
+		result.walk(plan)
+	}
+	if stats != nil {
+		result.NReturned = explainInt(stats["nReturned"])
+		result.DocsExamined = explainInt(stats["totalDocsExamined"])
+		result.KeysExamined = explainInt(stats["totalKeysExamined"])
+		result.ExecutionTime = time.Duration(explainInt(stats["executionTimeMillis"])) * time.Millisecond
+	}
+	return
+}
+
+//walk will collect the stage and index from plan tree, the sbe plan and sharded plan is supported.
+func (e *ExplainResult) walk(plan bson.M) {
+	if plan == nil {
+		return
+	}
+	if sub, ok := plan["queryPlan"].(bson.M); ok {
+		plan = sub
+	}
+	if stage, ok := plan["stage"].(string); ok {
+		e.Stages = append(e.Stages, stage)
+	}
+	if name, ok := plan["indexName"].(string); ok {
+		var having = false
+		for _, index := range e.Indexes {
+			having = having || index == name
+		}
+		if !having {
+			e.Indexes = append(e.Indexes, name)
+		}
+	}
+	if input, ok := plan["inputStage"].(bson.M); ok {
+		e.walk(input)
+	}
+	for _, key := range []string{"inputStages", "shards"} {
+		list, _ := plan[key].([]interface{})
+		for _, one := range list {
+			sub, _ := one.(bson.M)
+			if winning, ok := sub["winningPlan"].(bson.M); ok {
+				sub = winning
+			}
+			e.walk(sub)
+		}
+	}
+}
+
+func explainInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+var lintRunning = make(chan int, 4)
+var lintWaiter = sync.WaitGroup{}
+
+type lintArgs struct {
+	Query  bson.Raw `bson:"q"`
+	Fields bson.Raw `bson:"f"`
+}
+
+//lintFindAsync will copy the find arguments and run lintFind in background, which is not adding latency to find.
+//it is skipped when 4 lint is running.
+func (c *Collection) lintFindAsync(query, fields interface{}, skip, limit int) {
+	select {
+	case lintRunning <- 1:
+	default:
+		return
+	}
+	lintWaiter.Add(1)
+	var args = &lintArgs{}
+	bys, err := bson.Marshal(bson.M{"q": query, "f": fields}) //the caller may change the query after find.
+	if err == nil {
+		err = bson.Unmarshal(bys, args)
+	}
+	if err != nil {
+		<-lintRunning
+		lintWaiter.Done()
+		warnLog("query lint copy find on %v.%v fail with %v", c.DbName, c.Name, err)
+		return
+	}
+	go func() {
+		defer func() {
+			<-lintRunning
+			lintWaiter.Done()
+		}()
+		c.lintFind(args.Query, args.Fields, skip, limit)
+	}()
+}
+
+//lintFind will explain the find and log warning when it is COLLSCAN or in-memory SORT, it is called when QueryLint is enabled.
+func (c *Collection) lintFind(query, fields bson.Raw, skip, limit int) {
+	var op = &ExplainFind{
+		Skip:  skip,
+		Limit: limit,
+	}
+	var filter, sort bson.Raw
+	var legacy struct { //the legacy query modifiers.
+		Query   bson.Raw `bson:"$query"`
+		Orderby bson.Raw `bson:"$orderby"`
+	}
+	if query.Kind == 0x03 {
+		filter = query
+		if query.Unmarshal(&legacy) == nil && legacy.Query.Kind == 0x03 {
+			filter, sort = legacy.Query, legacy.Orderby
+		}
+		op.Filter = filter
+	}
+	if fields.Kind == 0x03 {
+		op.Projection = fields
+	}
+	var cmd = op.command(c.Name)
+	if sort.Kind == 0x03 {
+		cmd = append(cmd, bson.DocElem{Name: "sort", Value: sort})
+	}
+	result, err := c.explain(cmd, "queryPlanner")
+	if err != nil {
+		warnLog("query lint explain find on %v.%v fail with %v", c.DbName, c.Name, err)
+		return
+	}
+	var problems []string
+	if result.HasStage("COLLSCAN") {
+		problems = append(problems, "COLLSCAN")
+	}
+	if result.HasStage("SORT") {
+		problems = append(problems, "in-memory SORT")
+	}
+	if len(problems) > 0 {
+		warnLog("query lint find on %v.%v by %s is %v, the plan is %v", c.DbName, c.Name, lintFilter(filter), strings.Join(problems, " and "), result.Stages)
+	}
+}
+
+//lintFilter will format the filter to json for log, it is formatted by fmt when it can't be marshaled to json.
+func lintFilter(filter bson.Raw) string {
+	var doc = bson.M{}
+	if filter.Kind == 0x03 {
+		filter.Unmarshal(&doc)
+	}
+	text, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Sprintf("%v", doc)
+	}
+	return string(text)
+}
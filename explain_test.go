@@ -0,0 +1,192 @@
+package mongoc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestParseExplain(t *testing.T) {
+	//classic
+	result := parseExplain(bson.M{
+		"queryPlanner": bson.M{
+			"winningPlan": bson.M{
+				"stage": "SORT",
+				"inputStage": bson.M{
+					"stage":      "FETCH",
+					"inputStage": bson.M{"stage": "IXSCAN", "indexName": "a_1"},
+				},
+			},
+		},
+		"executionStats": bson.M{"nReturned": 2, "totalDocsExamined": int64(3), "totalKeysExamined": 4, "executionTimeMillis": 5.0},
+	})
+	if fmt.Sprintf("%v %v", result.Stages, result.Indexes) != "[SORT FETCH IXSCAN] [a_1]" || !result.HasStage("SORT") || result.HasStage("COLLSCAN") {
+		t.Errorf("result:%v", result)
+		return
+	}
+	if result.NReturned != 2 || result.DocsExamined != 3 || result.KeysExamined != 4 || result.ExecutionTime != 5*time.Millisecond {
+		t.Errorf("result:%v", result)
+		return
+	}
+	//sbe and or
+	result = parseExplain(bson.M{
+		"queryPlanner": bson.M{
+			"winningPlan": bson.M{
+				"queryPlan": bson.M{
+					"stage": "OR",
+					"inputStages": []interface{}{
+						bson.M{"stage": "IXSCAN", "indexName": "a_1"},
+						bson.M{"stage": "IXSCAN", "indexName": "b_1"},
+						bson.M{"stage": "IXSCAN", "indexName": "a_1"},
+					},
+				},
+			},
+		},
+	})
+	if fmt.Sprintf("%v %v", result.Stages, result.Indexes) != "[OR IXSCAN IXSCAN IXSCAN] [a_1 b_1]" || result.ExecutionTime != 0 {
+		t.Errorf("result:%v", result)
+		return
+	}
+	//aggregate and sharded
+	result = parseExplain(bson.M{
+		"stages": []interface{}{
+			bson.M{
+				"$cursor": bson.M{
+					"queryPlanner": bson.M{
+						"winningPlan": bson.M{
+							"stage": "SHARD_MERGE",
+							"shards": []interface{}{
+								bson.M{"winningPlan": bson.M{"stage": "COLLSCAN"}},
+							},
+						},
+					},
+					"executionStats": bson.M{"nReturned": 1},
+				},
+			},
+			bson.M{"$group": bson.M{}},
+		},
+	})
+	if fmt.Sprintf("%v", result.Stages) != "[SHARD_MERGE COLLSCAN]" || result.NReturned != 1 {
+		t.Errorf("result:%v", result)
+		return
+	}
+	if result = parseExplain(bson.M{}); len(result.Stages) != 0 {
+		t.Errorf("result:%v", result)
+		return
+	}
+}
+
+func TestExplain(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_explain")
+	col.Drop()
+	col.CheckIndex(false, &Index{Name: "a_1", Key: []string{"a"}})
+	for i := 0; i < 10; i++ {
+		col.Insert(bson.M{"_id": i, "a": i, "b": i % 2})
+	}
+	result, err := col.Explain(&ExplainFind{Filter: bson.M{"a": bson.M{"$gte": 5}}, Sort: []string{"-a"}, Limit: 3}, "")
+	if err != nil || result.HasStage("COLLSCAN") || result.HasStage("SORT") || result.Indexes[0] != "a_1" || result.NReturned != 3 {
+		t.Errorf("result:%v,err:%v", result, err)
+		return
+	}
+	result, err = col.Explain(&ExplainFind{Filter: bson.M{"b": 1}, Sort: []string{"b"}}, "queryPlanner")
+	if err != nil || !result.HasStage("COLLSCAN") || !result.HasStage("SORT") || result.NReturned != 0 {
+		t.Errorf("result:%v,err:%v", result, err)
+		return
+	}
+	for _, op := range []ExplainOp{
+		&ExplainAggregate{Pipeline: NewPipeline().Match(bson.M{"a": 1}).Group("$b", bson.M{"n": bson.M{"$sum": 1}})},
+		&ExplainCount{Query: bson.M{"a": 1}},
+		&ExplainDistinct{Key: "b", Query: bson.M{"a": 1}},
+		&ExplainUpdate{Selector: bson.M{"a": 1}, Update: bson.M{"$set": bson.M{"c": 1}}},
+		&ExplainDelete{Selector: bson.M{"a": 1}, Single: true},
+	} {
+		result, err = col.Explain(op, "executionStats")
+		if err != nil || len(result.Indexes) < 1 || result.Indexes[0] != "a_1" {
+			t.Errorf("op:%T,result:%v,err:%v", op, result, err)
+			return
+		}
+	}
+	if count, _ := col.Count(bson.M{"c": 1}, 0, 0); count != 0 {
+		t.Error("update is applied")
+		return
+	}
+	if count, _ := col.Count(nil, 0, 0); count != 10 {
+		t.Error("delete is applied")
+		return
+	}
+	_, err = col.Explain(&ExplainFind{Filter: TestExplain}, "")
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = col.Explain(&ExplainFind{}, "xx")
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestQueryLint(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 1, 1)
+	SetQueryLint(true)
+	col := pool.C("test", "mongoc_explain")
+	col.Drop()
+	col.CheckIndex(false, &Index{Name: "a_1", Key: []string{"a"}})
+	col.Insert(bson.M{"_id": 1, "a": 1, "b": 1})
+	var logs []string
+	LogHandler = func(logLevel LogLevel, logDomain, message string) {
+		if strings.HasPrefix(message, "query lint") {
+			logs = append(logs, message)
+		}
+	}
+	defer func() {
+		LogHandler = DefaultLogHandler
+		SetQueryLint(false)
+	}()
+	var docs []bson.M
+	err := col.Find(bson.M{"a": 1}, nil, 0, 0, &docs)
+	lintWaiter.Wait()
+	if err != nil || len(logs) != 0 {
+		t.Errorf("logs:%v,err:%v", logs, err)
+		return
+	}
+	err = col.Find(bson.M{"b": 1}, nil, 0, 0, &docs)
+	lintWaiter.Wait()
+	if err != nil || len(logs) != 1 || !strings.Contains(logs[0], "COLLSCAN") {
+		t.Errorf("logs:%v,err:%v", logs, err)
+		return
+	}
+	err = col.Find(bson.M{"$query": bson.M{"a": 1}, "$orderby": bson.M{"b": 1}}, nil, 0, 0, &docs)
+	lintWaiter.Wait()
+	if err != nil || len(logs) != 2 || !strings.Contains(logs[1], "in-memory SORT") {
+		t.Errorf("logs:%v,err:%v", logs, err)
+		return
+	}
+}
+
+func TestLintFilter(t *testing.T) {
+	for _, c := range []struct {
+		filter float64
+		text   string
+	}{
+		{1, `{"a":1}`},
+		{math.NaN(), `map[a:NaN]`}, //json is not supported NaN.
+	} {
+		bys, _ := bson.Marshal(bson.M{"q": bson.M{"a": c.filter}})
+		args := &lintArgs{}
+		err := bson.Unmarshal(bys, args)
+		if err != nil || lintFilter(args.Query) != c.text {
+			t.Errorf("text:%v,err:%v", lintFilter(args.Query), err)
+			return
+		}
+	}
+	if lintFilter(bson.Raw{}) != "{}" {
+		t.Error(lintFilter(bson.Raw{}))
+		return
+	}
+}
@@ -0,0 +1,158 @@
+package mongoc
+
+import bson "gopkg.in/bson.v2"
+
+//EarthRadius is the equatorial radius of earth in meters, it is used to convert distance to radians.
+const EarthRadius = 6378100.0
+
+//Point is the GeoJSON point, the coordinates is [longitude, latitude].
+//for more https://docs.mongodb.com/manual/reference/geojson/
+type Point struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"`
+}
+
+//NewPoint will create the GeoJSON point by longitude and latitude.
+func NewPoint(lng, lat float64) *Point {
+	return &Point{
+		Type:        "Point",
+		Coordinates: []float64{lng, lat},
+	}
+}
+
+//LineString is the GeoJSON line string.
+type LineString struct {
+	Type        string      `bson:"type" json:"type"`
+	Coordinates [][]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+//NewLineString will create the GeoJSON line string by multi [longitude, latitude] position.
+func NewLineString(positions ...[]float64) *LineString {
+	return &LineString{
+		Type:        "LineString",
+		Coordinates: positions,
+	}
+}
+
+//Polygon is the GeoJSON polygon, the first ring is exterior ring and others is interior ring.
+type Polygon struct {
+	Type        string        `bson:"type" json:"type"`
+	Coordinates [][][]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+//NewPolygon will create the GeoJSON polygon by multi ring of [longitude, latitude] position,
+//the ring is closed by appending the first position when it is not closed.
+func NewPolygon(rings ...[][]float64) *Polygon {
+	var coordinates = [][][]float64{}
+	for _, ring := range rings {
+		if len(ring) > 0 && !samePosition(ring[0], ring[len(ring)-1]) {
+			ring = append(append([][]float64{}, ring...), ring[0])
+		}
+		coordinates = append(coordinates, ring)
+	}
+	return &Polygon{
+		Type:        "Polygon",
+		Coordinates: coordinates,
+	}
+}
+
+func samePosition(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//MultiPolygon is the GeoJSON multi polygon.
+type MultiPolygon struct {
+	Type        string          `bson:"type" json:"type"`
+	Coordinates [][][][]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+//NewMultiPolygon will create the GeoJSON multi polygon by multi polygon.
+func NewMultiPolygon(polygons ...*Polygon) *MultiPolygon {
+	var coordinates = [][][][]float64{}
+	for _, polygon := range polygons {
+		coordinates = append(coordinates, polygon.Coordinates)
+	}
+	return &MultiPolygon{
+		Type:        "MultiPolygon",
+		Coordinates: coordinates,
+	}
+}
+
+//Near return the $near operator to find document by distance from point, following:
+//
+//	col.Find(bson.M{"loc": mongoc.Near(mongoc.NewPoint(120, 30), 0, 1000)}, nil, 0, 0, &docs)
+//
+//the distance is in meters and it is omitted when it is not greater than 0, the field must has 2dsphere index.
+func Near(point *Point, minDistance, maxDistance float64) bson.D {
+	var near = bson.D{
+		{
+			Name:  "$geometry",
+			Value: point,
+		},
+	}
+	if minDistance > 0 {
+		near = append(near, bson.DocElem{Name: "$minDistance", Value: minDistance})
+	}
+	if maxDistance > 0 {
+		near = append(near, bson.DocElem{Name: "$maxDistance", Value: maxDistance})
+	}
+	return bson.D{
+		{
+			Name:  "$near",
+			Value: near,
+		},
+	}
+}
+
+//GeoWithin return the $geoWithin operator to find document which is within the geometry, the geometry is Polygon/MultiPolygon.
+func GeoWithin(geometry interface{}) bson.D {
+	return bson.D{
+		{
+			Name: "$geoWithin",
+			Value: bson.D{
+				{
+					Name:  "$geometry",
+					Value: geometry,
+				},
+			},
+		},
+	}
+}
+
+//GeoWithinSphere return the $geoWithin operator to find document which is within the circle by center and radius in meters.
+func GeoWithinSphere(center *Point, radius float64) bson.D {
+	return bson.D{
+		{
+			Name: "$geoWithin",
+			Value: bson.D{
+				{
+					Name:  "$centerSphere",
+					Value: []interface{}{center.Coordinates, radius / EarthRadius},
+				},
+			},
+		},
+	}
+}
+
+//GeoIntersects return the $geoIntersects operator to find document which is intersected with the geometry.
+func GeoIntersects(geometry interface{}) bson.D {
+	return bson.D{
+		{
+			Name: "$geoIntersects",
+			Value: bson.D{
+				{
+					Name:  "$geometry",
+					Value: geometry,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package mongoc
+
+import (
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestGeoJSON(t *testing.T) {
+	polygon := NewPolygon([][]float64{{0, 0}, {0, 1}, {1, 1}}, [][]float64{{0.1, 0.1}, {0.1, 0.2}, {0.2, 0.2}, {0.1, 0.1}}, nil)
+	if len(polygon.Coordinates[0]) != 4 || len(polygon.Coordinates[1]) != 4 || len(polygon.Coordinates[2]) != 0 {
+		t.Errorf("polygon error:%v", polygon.Coordinates)
+		return
+	}
+	multi := NewMultiPolygon(polygon, NewPolygon([][]float64{{2, 2}, {2, 3}, {3, 3}}))
+	bys, err := bson.Marshal(multi)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var doc bson.M
+	bson.Unmarshal(bys, &doc)
+	if doc["type"] != "MultiPolygon" || len(doc["coordinates"].([]interface{})) != 2 {
+		t.Errorf("multi polygon error:%v", doc)
+		return
+	}
+	if near := Near(NewPoint(1, 2), 10, 0); near[0].Name != "$near" || len(near[0].Value.(bson.D)) != 2 {
+		t.Errorf("near error:%v", near)
+		return
+	}
+	if within := GeoWithinSphere(NewPoint(1, 2), EarthRadius); within[0].Value.(bson.D)[0].Value.([]interface{})[1] != 1.0 {
+		t.Errorf("within error:%v", within)
+		return
+	}
+}
+
+func TestGeoQuery(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_geo")
+	col.Drop()
+	err := col.CheckIndex(false, &Index{
+		Name: "loc_2dsphere",
+		Key:  []string{"$2dsphere:loc", "-a"},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	indexes, err := col.ListIndexes()
+	if err != nil || len(indexes) != 2 || fmt.Sprintf("%v", indexes[1].Key) != "[$2dsphere:loc -a]" {
+		t.Errorf("indexes:%v,err:%v", indexes, err)
+		return
+	}
+	for i := 0; i < 5; i++ {
+		err = col.Insert(bson.M{"_id": i, "loc": NewPoint(float64(i)/100, 0), "a": i})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	var docs []bson.M
+	err = col.Find(bson.M{"loc": Near(NewPoint(0, 0), 0, 2000)}, nil, 0, 0, &docs)
+	if err != nil || len(docs) != 2 || docs[0]["_id"] != 0 {
+		t.Errorf("docs:%v,err:%v", docs, err)
+		return
+	}
+	docs = nil
+	err = col.Find(bson.M{"loc": GeoWithin(NewPolygon([][]float64{{0.015, -1}, {0.015, 1}, {1, 1}, {1, -1}}))}, nil, 0, 0, &docs)
+	if err != nil || len(docs) != 3 {
+		t.Errorf("docs:%v,err:%v", docs, err)
+		return
+	}
+	docs = nil
+	err = col.Find(bson.M{"loc": GeoWithinSphere(NewPoint(0, 0), 2000)}, nil, 0, 0, &docs)
+	if err != nil || len(docs) != 2 {
+		t.Errorf("docs:%v,err:%v", docs, err)
+		return
+	}
+	docs = nil
+	err = col.Find(bson.M{"loc": GeoIntersects(NewPoint(0.02, 0))}, nil, 0, 0, &docs)
+	if err != nil || len(docs) != 1 || docs[0]["_id"] != 2 {
+		t.Errorf("docs:%v,err:%v", docs, err)
+		return
+	}
+}
@@ -0,0 +1,525 @@
+package mongoc
+
+/*
+#include <mongoc.h>
+*/
+import "C"
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unsafe"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//File is the GridFS file document.
+//for more https://docs.mongodb.com/manual/core/gridfs/#the-files-collection
+type File struct {
+	ID         interface{} `bson:"_id"`
+	Length     int64       `bson:"length"`
+	ChunkSize  int         `bson:"chunkSize"`
+	UploadDate time.Time   `bson:"uploadDate"`
+	Filename   string      `bson:"filename"`
+	Metadata   bson.M      `bson:"metadata,omitempty"`
+}
+
+//Bucket is the wrapper of C.mongoc_gridfs_bucket_t.
+type Bucket struct {
+	Pool      Poolable
+	DbName    string
+	Name      string //the bucket name, default is fs.
+	ChunkSize int    //the chunk size in bytes of new file, default is 255KB.
+}
+
+//GridFS will create the GridFS bucket by database name and bucket name.
+func (p *Pool) GridFS(dbname, bucketName string) *Bucket {
+	if p.closed {
+		panic("pool is closed")
+	}
+	if len(bucketName) < 1 {
+		bucketName = "fs"
+	}
+	return &Bucket{
+		Pool:      p,
+		DbName:    dbname,
+		Name:      bucketName,
+		ChunkSize: 255 * 1024,
+	}
+}
+
+//Files return the files collection of bucket.
+func (b *Bucket) Files() *Collection {
+	return &Collection{
+		Name:   b.Name + ".files",
+		DbName: b.DbName,
+		Pool:   b.Pool,
+	}
+}
+
+//Chunks return the chunks collection of bucket.
+func (b *Bucket) Chunks() *Collection {
+	return &Collection{
+		Name:   b.Name + ".chunks",
+		DbName: b.DbName,
+		Pool:   b.Pool,
+	}
+}
+
+type rawBucket struct {
+	db  *C.mongoc_database_t
+	raw *C.mongoc_gridfs_bucket_t
+}
+
+func (r *rawBucket) Release() {
+	C.mongoc_gridfs_bucket_destroy(r.raw)
+	C.mongoc_database_destroy(r.db)
+}
+
+//rawBucket will create the raw bucket on client.
+func (b *Bucket) rawBucket(client *Client) (bucket *rawBucket, err error) {
+	if client.session != nil { //the bucket operation is not session bound.
+		err = ErrSessionNotSupported
+		return
+	}
+	var opts = bson.M{
+		"bucketName": b.Name,
+	}
+	if b.ChunkSize > 0 {
+		opts["chunkSizeBytes"] = int32(b.ChunkSize)
+	}
+	rawOpts, err := parseBSON(opts)
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawOpts)
+	cdbname := C.CString(b.DbName)
+	defer C.free(unsafe.Pointer(cdbname))
+	var db = C.mongoc_client_get_database(client.raw, cdbname)
+	var berr C.bson_error_t
+	var raw = C.mongoc_gridfs_bucket_new(db, rawOpts, nil, &berr)
+	if raw == nil {
+		C.mongoc_database_destroy(db)
+		err = parseBSONError(&berr)
+		client.LastError = err
+		return
+	}
+	bucket = &rawBucket{
+		db:  db,
+		raw: raw,
+	}
+	return
+}
+
+//withIDValue will call the call back with the id as C.bson_value_t.
+func withIDValue(id interface{}, call func(value *C.bson_value_t)) (err error) {
+	rawID, err := parseBSON(bson.M{"_id": id})
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawID)
+	var iter C.bson_iter_t
+	ckey := C.CString("_id")
+	defer C.free(unsafe.Pointer(ckey))
+	if !C.bson_iter_init_find(&iter, rawID, ckey) {
+		return fmt.Errorf("parse id(%v) fail", id)
+	}
+	call(C.bson_iter_value(&iter))
+	return
+}
+
+//UploadStream is the io.WriteCloser to upload file to GridFS,
+//the file is not saved until Close is called.
+type UploadStream struct {
+	ID     interface{} //the file id.
+	client *Client
+	bucket *rawBucket
+	raw    *C.mongoc_stream_t
+}
+
+//OpenUploadStream will open the stream to upload file by filename and metadata, the metadata can be nil.
+func (b *Bucket) OpenUploadStream(filename string, metadata interface{}) (stream *UploadStream, err error) {
+	return b.OpenUploadStreamWithID(bson.NewObjectId(), filename, metadata)
+}
+
+//OpenUploadStreamWithID will open the stream to upload file by id, filename and metadata, the metadata can be nil.
+func (b *Bucket) OpenUploadStreamWithID(id interface{}, filename string, metadata interface{}) (stream *UploadStream, err error) {
+	var opts = bson.M{}
+	if metadata != nil {
+		opts["metadata"] = metadata
+	}
+	rawOpts, err := parseBSON(opts)
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawOpts)
+	var client = b.Pool.Pop()
+	bucket, err := b.rawBucket(client)
+	if err != nil {
+		client.Close()
+		return
+	}
+	cfilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cfilename))
+	var berr C.bson_error_t
+	var raw *C.mongoc_stream_t
+	err = withIDValue(id, func(value *C.bson_value_t) {
+		raw = C.mongoc_gridfs_bucket_open_upload_stream_with_id(bucket.raw, value, cfilename, rawOpts, &berr)
+	})
+	if err == nil && raw == nil {
+		err = parseBSONError(&berr)
+		client.LastError = err
+	}
+	if err != nil {
+		bucket.Release()
+		client.Close()
+		return
+	}
+	stream = &UploadStream{
+		ID:     id,
+		client: client,
+		bucket: bucket,
+		raw:    raw,
+	}
+	return
+}
+
+//Write is the io.Writer impl.
+func (u *UploadStream) Write(p []byte) (n int, err error) {
+	if u.raw == nil {
+		return 0, os.ErrClosed
+	}
+	if len(p) < 1 {
+		return
+	}
+	n = int(C.mongoc_stream_write(u.raw, unsafe.Pointer(&p[0]), C.size_t(len(p)), 0))
+	if n < 0 {
+		n = 0
+		err = u.streamError()
+	}
+	return
+}
+
+func (u *UploadStream) streamError() (err error) {
+	var berr C.bson_error_t
+	if C.mongoc_gridfs_bucket_stream_error(u.raw, &berr) {
+		err = parseBSONError(&berr)
+		u.client.LastError = err
+	} else {
+		err = fmt.Errorf("gridfs stream fail")
+	}
+	return
+}
+
+//Close will save the file to GridFS and release the stream.
+func (u *UploadStream) Close() (err error) {
+	if u.raw == nil {
+		return os.ErrClosed
+	}
+	if C.mongoc_stream_close(u.raw) != 0 {
+		err = u.streamError()
+	}
+	u.release()
+	return
+}
+
+//Abort will abort the uploading and remove the uploaded chunks.
+func (u *UploadStream) Abort() (err error) {
+	if u.raw == nil {
+		return os.ErrClosed
+	}
+	if !C.mongoc_gridfs_bucket_abort_upload(u.raw) {
+		err = u.streamError()
+	}
+	u.release()
+	return
+}
+
+func (u *UploadStream) release() {
+	C.mongoc_stream_destroy(u.raw)
+	u.raw = nil
+	u.bucket.Release()
+	u.client.Close()
+}
+
+//UploadFromReader will upload the file from reader and return the file id.
+func (b *Bucket) UploadFromReader(filename string, reader io.Reader, metadata interface{}) (id interface{}, err error) {
+	stream, err := b.OpenUploadStream(filename, metadata)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(stream, reader)
+	if err != nil {
+		stream.Abort()
+		return
+	}
+	err = stream.Close()
+	id = stream.ID
+	return
+}
+
+//Find the file document by filter and options, the opts can be nil.
+//for more http://mongoc.org/libmongoc/current/mongoc_gridfs_bucket_find.html
+func (b *Bucket) Find(filter, opts interface{}, val interface{}) (err error) {
+	if filter == nil {
+		filter = map[string]interface{}{}
+	}
+	if opts == nil {
+		opts = map[string]interface{}{}
+	}
+	rawFilter, err := parseBSON(filter)
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawFilter)
+	rawOpts, err := parseBSON(opts)
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawOpts)
+	var client = b.Pool.Pop()
+	defer client.Close()
+	bucket, err := b.rawBucket(client)
+	if err != nil {
+		return
+	}
+	defer bucket.Release()
+	var cursor = C.mongoc_gridfs_bucket_find(bucket.raw, rawFilter, rawOpts)
+	err = parseCursor(client, cursor, val)
+	C.mongoc_cursor_destroy(cursor)
+	return
+}
+
+//Delete will delete the file and its chunks by id.
+func (b *Bucket) Delete(id interface{}) (err error) {
+	var client = b.Pool.Pop()
+	defer client.Close()
+	bucket, err := b.rawBucket(client)
+	if err != nil {
+		return
+	}
+	defer bucket.Release()
+	var berr C.bson_error_t
+	var done C.bool
+	err = withIDValue(id, func(value *C.bson_value_t) {
+		done = C.mongoc_gridfs_bucket_delete_by_id(bucket.raw, value, &berr)
+	})
+	if err == nil && !done {
+		err = parseBSONError(&berr)
+		client.LastError = err
+	}
+	return
+}
+
+//Rename will rename the file by id, return ErrNotFound when file not found.
+func (b *Bucket) Rename(id interface{}, newFilename string) (err error) {
+	_, err = b.Files().UpdateOne(bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"filename": newFilename,
+		},
+	}, nil)
+	return
+}
+
+//Drop will drop the files and chunks collection of bucket.
+func (b *Bucket) Drop() (err error) {
+	for _, col := range []*Collection{b.Files(), b.Chunks()} {
+		err = col.Drop()
+		if berr, ok := err.(*BSONError); ok && berr.IsCollectionNotExist() {
+			err = nil
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//DownloadStream is the io.ReadSeekCloser to download file from GridFS,
+//it reads the chunks collection by chunk index, so seeking is not needed to read the skipped data.
+type DownloadStream struct {
+	File   *File
+	bucket *Bucket
+	offset int64
+	chunkN int64
+	chunk  []byte
+	closed bool
+}
+
+type chunkDoc struct {
+	Data []byte `bson:"data"`
+}
+
+//OpenDownloadStream will open the stream to download file by id, return ErrNotFound when file not found.
+func (b *Bucket) OpenDownloadStream(id interface{}) (stream *DownloadStream, err error) {
+	var file = &File{}
+	err = b.Files().FindOne(bson.M{"_id": id}, nil, file)
+	if err != nil {
+		return
+	}
+	stream, err = b.newDownloadStream(file)
+	return
+}
+
+//OpenDownloadStreamByName will open the stream to download the latest revision file by filename,
+//return ErrNotFound when file not found.
+func (b *Bucket) OpenDownloadStreamByName(filename string) (stream *DownloadStream, err error) {
+	var file = &File{}
+	err = b.Files().Aggregate(NewPipeline().Match(bson.M{"filename": filename}).Sort("-uploadDate").Limit(1), nil, file)
+	if err != nil {
+		return
+	}
+	stream, err = b.newDownloadStream(file)
+	return
+}
+
+//newDownloadStream will create the stream by file, it return error when the chunkSize of file is invalid.
+func (b *Bucket) newDownloadStream(file *File) (stream *DownloadStream, err error) {
+	if file.ChunkSize <= 0 {
+		err = fmt.Errorf("the chunkSize(%v) of file(%v) is invalid", file.ChunkSize, file.ID)
+		return
+	}
+	stream = &DownloadStream{
+		File:   file,
+		bucket: b,
+		chunkN: -1,
+	}
+	return
+}
+
+//Read is the io.Reader impl.
+func (d *DownloadStream) Read(p []byte) (n int, err error) {
+	if d.closed {
+		return 0, os.ErrClosed
+	}
+	for n < len(p) && d.offset < d.File.Length {
+		var chunkN = d.offset / int64(d.File.ChunkSize)
+		if chunkN != d.chunkN {
+			var chunk = &chunkDoc{}
+			err = d.bucket.Chunks().FindOne(bson.M{"files_id": d.File.ID, "n": chunkN}, nil, chunk)
+			if err == ErrNotFound {
+				err = fmt.Errorf("chunk %v of file(%v) is missing", chunkN, d.File.ID)
+			}
+			if err != nil {
+				return
+			}
+			d.chunkN, d.chunk = chunkN, chunk.Data
+		}
+		var start = d.offset - chunkN*int64(d.File.ChunkSize)
+		if start >= int64(len(d.chunk)) {
+			err = fmt.Errorf("chunk %v of file(%v) is truncated", chunkN, d.File.ID)
+			return
+		}
+		var copied = copy(p[n:], d.chunk[start:])
+		n += copied
+		d.offset += int64(copied)
+	}
+	if n < 1 && d.offset >= d.File.Length {
+		err = io.EOF
+	}
+	return
+}
+
+//Seek is the io.Seeker impl.
+func (d *DownloadStream) Seek(offset int64, whence int) (int64, error) {
+	if d.closed {
+		return 0, os.ErrClosed
+	}
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = d.offset + offset
+	case io.SeekEnd:
+		target = d.File.Length + offset
+	default:
+		return 0, fmt.Errorf("invalid whence(%v)", whence)
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("negative position(%v)", target)
+	}
+	d.offset = target
+	return target, nil
+}
+
+//Close will close the stream.
+func (d *DownloadStream) Close() error {
+	if d.closed {
+		return os.ErrClosed
+	}
+	d.closed = true
+	d.chunk = nil
+	return nil
+}
+
+//Stat return the fs.FileInfo of file.
+func (d *DownloadStream) Stat() (fs.FileInfo, error) {
+	return &fileInfo{file: d.File}, nil
+}
+
+//Readdir is the http.File impl, the GridFS is not supported directory.
+func (d *DownloadStream) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, fmt.Errorf("readdir is not supported on GridFS file")
+}
+
+type fileInfo struct {
+	file *File
+}
+
+func (f *fileInfo) Name() string {
+	return path.Base(f.file.Filename)
+}
+
+func (f *fileInfo) Size() int64 {
+	return f.file.Length
+}
+
+func (f *fileInfo) Mode() fs.FileMode {
+	return 0444
+}
+
+func (f *fileInfo) ModTime() time.Time {
+	return f.file.UploadDate
+}
+
+func (f *fileInfo) IsDir() bool {
+	return false
+}
+
+func (f *fileInfo) Sys() interface{} {
+	return f.file
+}
+
+//FS return the fs.FS adapter of bucket, the file is opened by filename.
+func (b *Bucket) FS() fs.FS {
+	return &bucketFS{bucket: b}
+}
+
+//FileSystem return the http.FileSystem adapter of bucket, it can be served by http.FileServer with range support.
+func (b *Bucket) FileSystem() http.FileSystem {
+	return http.FS(b.FS())
+}
+
+type bucketFS struct {
+	bucket *Bucket
+}
+
+func (b *bucketFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	stream, err := b.bucket.OpenDownloadStreamByName(strings.TrimPrefix(name, "/"))
+	if err == ErrNotFound {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return stream, nil
+}
@@ -0,0 +1,200 @@
+package mongoc
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestGridFS(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	bucket := pool.GridFS("test", "gridfs")
+	bucket.ChunkSize = 10
+	err := bucket.Drop()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	//
+	//upload
+	stream, err := bucket.OpenUploadStream("a.txt", bson.M{"x": 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = stream.Write(data[:15])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = stream.Write(data[15:])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = stream.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if stream.Close() == nil {
+		t.Error("not error")
+		return
+	}
+	id, err := bucket.UploadFromReader("b.txt", bytes.NewBuffer(data[:5]), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//
+	//find
+	var files []*File
+	err = bucket.Find(nil, nil, &files)
+	if err != nil || len(files) != 2 {
+		t.Errorf("files:%v,err:%v", files, err)
+		return
+	}
+	//
+	//download
+	down, err := bucket.OpenDownloadStream(stream.ID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if down.File.Length != int64(len(data)) || down.File.Metadata["x"] != 1 {
+		t.Errorf("file:%v", down.File)
+		return
+	}
+	readed, err := ioutil.ReadAll(down)
+	if err != nil || !bytes.Equal(readed, data) {
+		t.Errorf("readed:%s,err:%v", readed, err)
+		return
+	}
+	_, err = down.Seek(-12, io.SeekEnd)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(down, buf)
+	if err != nil || string(buf) != "opqrs" {
+		t.Errorf("buf:%s,err:%v", buf, err)
+		return
+	}
+	down.Close()
+	_, err = bucket.OpenDownloadStream(bson.NewObjectId())
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	//
+	//http
+	server := httptest.NewServer(http.FileServer(bucket.FileSystem()))
+	defer server.Close()
+	req, _ := http.NewRequest("GET", server.URL+"/a.txt", nil)
+	req.Header.Set("Range", "bytes=8-12")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	readed, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusPartialContent || string(readed) != "89abc" {
+		t.Errorf("status:%v,readed:%s", res.StatusCode, readed)
+		return
+	}
+	res, err = http.Get(server.URL + "/none.txt")
+	if err != nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("res:%v,err:%v", res, err)
+		return
+	}
+	res.Body.Close()
+	//
+	//rename
+	err = bucket.Rename(id, "c.txt")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	down, err = bucket.OpenDownloadStreamByName("c.txt")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	down.Close()
+	err = bucket.Rename(bson.NewObjectId(), "d.txt")
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	//
+	//delete
+	err = bucket.Delete(id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = bucket.Delete(id)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	//
+	//abort
+	stream, err = bucket.OpenUploadStream("e.txt", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	stream.Write(data)
+	err = stream.Abort()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = bucket.OpenDownloadStream(stream.ID)
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	//
+	//error
+	_, err = bucket.OpenUploadStream("f.txt", TestGridFS)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	err = bucket.Find(TestGridFS, nil, &files)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	err = bucket.Drop()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestNewDownloadStream(t *testing.T) {
+	bucket := &Bucket{}
+	for _, size := range []int{0, -1} {
+		_, err := bucket.newDownloadStream(&File{ID: 1, Length: 10, ChunkSize: size})
+		if err == nil {
+			t.Errorf("%v not error", size)
+			return
+		}
+	}
+	stream, err := bucket.newDownloadStream(&File{ID: 1, Length: 10, ChunkSize: 1})
+	if err != nil || stream.chunkN != -1 {
+		t.Errorf("stream:%v,err:%v", stream, err)
+		return
+	}
+}
@@ -0,0 +1,239 @@
+package mongoc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ErrLocked is the error when the lock is held by other owner.
+var ErrLocked = fmt.Errorf("locked by other")
+
+//ErrLockLost is the error when the lease is expired or acquired by other owner.
+var ErrLockLost = fmt.Errorf("lock lost")
+
+//MinLockTTL is the min ttl of lock lease.
+const MinLockTTL = 30 * time.Millisecond
+
+//Locker is the distributed lock/lease on collection by findAndModify, the lock document is
+//{_id:name,owner:owner,token:fencing token,expire:time}, following:
+//
+//	locker, err := col.NewLocker()
+//	lease, err := locker.TryAcquire("cron", "node-1", 10*time.Second)
+//	if err == ErrLocked {
+//		return
+//	}
+//	defer locker.Release(lease)
+//	select {
+//	case <-lease.Lost:
+//		//stop the work.
+//	case <-done:
+//	}
+//
+//the expire is checked by the client time, so the clock of all owners should be synchronized.
+type Locker struct {
+	C             *Collection
+	AutoRenew     bool          //renew the lease on background by TTL/3, default is true.
+	RetryInterval time.Duration //the interval to retry on Acquire, default is 500ms.
+	//
+	fence *Sequence
+}
+
+//Lease is the acquired lock.
+type Lease struct {
+	Name   string
+	Owner  string
+	Token  int64 //the fencing token, it is increasing on each acquisition.
+	TTL    time.Duration
+	Expire time.Time
+	Lost   chan error //the error will be sent when the lease is lost on auto renew.
+	//
+	lck  sync.Mutex
+	stop chan int
+}
+
+type lockDoc struct {
+	Owner  string    `bson:"owner"`
+	Token  int64     `bson:"token"`
+	Expire time.Time `bson:"expire"`
+}
+
+//fenceID is the sequence name of the fencing token, it has not expire field, so it is not removed by TTL index.
+const fenceID = "$fence"
+
+//NewLocker will create the locker on collection and check the TTL index for clearing expired lock.
+func (c *Collection) NewLocker() (locker *Locker, err error) {
+	err = c.CheckIndex(false, &Index{
+		Key:                []string{"expire"},
+		Name:               "expire_ttl",
+		ExpireAfterSeconds: 60,
+	})
+	if err != nil {
+		return
+	}
+	locker = &Locker{
+		C:             c,
+		AutoRenew:     true,
+		RetryInterval: 500 * time.Millisecond,
+		fence:         c.Sequence(fenceID),
+	}
+	return
+}
+
+//TryAcquire will try acquire the lock by name/owner once, it return ErrLocked when the lock is held by other.
+//the lock held by the same owner is extended and the fencing token is kept, so the leases of same owner share the lock,
+//and the lock is released when any of them is released.
+func (l *Locker) TryAcquire(name, owner string, ttl time.Duration) (lease *Lease, err error) {
+	if name == fenceID {
+		err = fmt.Errorf("the lock name(%v) is reserved", name)
+		return
+	}
+	if ttl < MinLockTTL { //the lease is renewed by ttl/3.
+		err = fmt.Errorf("the lock ttl(%v) must not be less than %v", ttl, MinLockTTL)
+		return
+	}
+	var now = time.Now()
+	var doc = &lockDoc{}
+	changed, err := l.C.FindAndModify(bson.M{
+		"_id":    name,
+		"owner":  owner,
+		"expire": bson.M{"$gte": now},
+	}, nil, bson.M{
+		"$set": bson.M{
+			"expire": now.Add(ttl),
+		},
+	}, nil, false, true, doc)
+	if err != nil {
+		return
+	}
+	if changed.Matched < 1 {
+		var token int64
+		token, err = l.fence.Next()
+		if err != nil {
+			return
+		}
+		_, err = l.C.FindAndModify(bson.M{
+			"_id":    name,
+			"expire": bson.M{"$lt": now},
+		}, nil, bson.M{
+			"$set": bson.M{
+				"owner":  owner,
+				"token":  token,
+				"expire": now.Add(ttl),
+			},
+		}, nil, true, true, doc)
+	}
+	if berr, ok := err.(*BSONError); ok && berr.Code == ErrDuplicateKey {
+		err = ErrLocked
+	}
+	if err != nil {
+		return
+	}
+	lease = &Lease{
+		Name:   name,
+		Owner:  owner,
+		Token:  doc.Token,
+		TTL:    ttl,
+		Expire: doc.Expire,
+		Lost:   make(chan error, 1),
+		stop:   make(chan int),
+	}
+	if l.AutoRenew {
+		go l.renew(lease)
+	}
+	return
+}
+
+//Acquire will wait and acquire the lock by name/owner until context is done.
+func (l *Locker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (lease *Lease, err error) {
+	var ticker = time.NewTicker(l.RetryInterval)
+	defer ticker.Stop()
+	for {
+		lease, err = l.TryAcquire(name, owner, ttl)
+		if err != ErrLocked {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+	}
+}
+
+//Refresh will extend the lease expire by TTL, it return ErrLockLost when the lease is not held.
+func (l *Locker) Refresh(lease *Lease) (err error) {
+	lease.lck.Lock()
+	defer lease.lck.Unlock()
+	var expire = time.Now().Add(lease.TTL)
+	_, err = l.C.UpdateOne(bson.M{
+		"_id":   lease.Name,
+		"owner": lease.Owner,
+		"token": lease.Token,
+	}, bson.M{
+		"$set": bson.M{
+			"expire": expire,
+		},
+	}, nil)
+	if err == ErrNotFound {
+		err = ErrLockLost
+	}
+	if err == nil {
+		lease.Expire = expire
+	}
+	return
+}
+
+//Release will release the lock and stop the auto renew, it return ErrLockLost when the lease is not held.
+func (l *Locker) Release(lease *Lease) (err error) {
+	lease.lck.Lock()
+	defer lease.lck.Unlock()
+	select {
+	case <-lease.stop:
+	default:
+		close(lease.stop)
+	}
+	result, err := l.C.DeleteOne(bson.M{
+		"_id":   lease.Name,
+		"owner": lease.Owner,
+		"token": lease.Token,
+	}, nil)
+	if err == nil && result.Deleted < 1 {
+		err = ErrLockLost
+	}
+	return
+}
+
+//renew will refresh the lease by TTL/3 until it is released or lost.
+func (l *Locker) renew(lease *Lease) {
+	var ticker = time.NewTicker(lease.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-lease.stop:
+			return
+		}
+		err := l.Refresh(lease)
+		if err == nil {
+			continue
+		}
+		lease.lck.Lock()
+		var expired = time.Now().After(lease.Expire)
+		lease.lck.Unlock()
+		if err != ErrLockLost && !expired { //retry on temp error.
+			warnLog("locker renew lease(%v) fail with %v", lease.Name, err)
+			continue
+		}
+		select {
+		case <-lease.stop: //released on refreshing.
+		default:
+			lease.Lost <- ErrLockLost
+		}
+		return
+	}
+}
@@ -0,0 +1,117 @@
+package mongoc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestLocker(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_lock")
+	col.RemoveAll(nil)
+	locker, err := col.NewLocker()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	lease, err := locker.TryAcquire("cron", "a", 300*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = locker.TryAcquire("cron", "b", time.Second)
+	if err != ErrLocked {
+		t.Error(err)
+		return
+	}
+	again, err := locker.TryAcquire("cron", "a", 300*time.Millisecond)
+	if err != nil || again.Token != lease.Token {
+		t.Errorf("again:%v,err:%v", again, err)
+		return
+	}
+	//the old lease is kept by acquiring again.
+	select {
+	case err = <-lease.Lost:
+		t.Errorf("lost with %v", err)
+		return
+	case <-time.After(200 * time.Millisecond):
+	}
+	_, err = locker.TryAcquire(fenceID, "a", time.Second)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	for _, ttl := range []time.Duration{0, -time.Second, time.Nanosecond} {
+		_, err = locker.TryAcquire("ttl", "a", ttl)
+		if err == nil {
+			t.Errorf("%v not error", ttl)
+			return
+		}
+	}
+	//
+	//auto renew
+	time.Sleep(500 * time.Millisecond)
+	_, err = locker.TryAcquire("cron", "b", time.Second)
+	if err != ErrLocked {
+		t.Error(err)
+		return
+	}
+	err = locker.Release(again)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = locker.Release(again)
+	if err != ErrLockLost {
+		t.Error(err)
+		return
+	}
+	err = locker.Refresh(again)
+	if err != ErrLockLost {
+		t.Error(err)
+		return
+	}
+	//
+	//acquire after expired
+	locker.AutoRenew = false
+	lease, err = locker.TryAcquire("cron", "b", 100*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = locker.Acquire(ctx, "cron", "c", time.Second)
+	if err != context.DeadlineExceeded {
+		t.Error(err)
+		return
+	}
+	leaseC, err := locker.Acquire(context.Background(), "cron", "c", time.Second)
+	if err != nil || leaseC.Token <= lease.Token {
+		t.Errorf("lease:%v,err:%v", leaseC, err)
+		return
+	}
+	err = locker.Refresh(lease)
+	if err != ErrLockLost {
+		t.Error(err)
+		return
+	}
+	//
+	//lost by deleted
+	locker.AutoRenew = true
+	leaseD, err := locker.TryAcquire("other", "d", 150*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	col.RemoveAll(bson.M{"_id": "other"})
+	select {
+	case <-leaseD.Lost:
+	case <-time.After(time.Second):
+		t.Error("not lost")
+		return
+	}
+}
@@ -0,0 +1,348 @@
+//Package migrate is the versioned data migration on mongodb, following:
+//
+//	func init() {
+//		migrate.Register(20240101, "rename user name", func(pool *mongoc.Pool) error {
+//			_, err := pool.C("app", "user").UpdateMany(nil, bson.M{"$rename": bson.M{"n": "name"}}, nil)
+//			return err
+//		}, func(pool *mongoc.Pool) error {
+//			_, err := pool.C("app", "user").UpdateMany(nil, bson.M{"$rename": bson.M{"name": "n"}}, nil)
+//			return err
+//		})
+//	}
+//
+//	func main() {
+//		err := migrate.Run(migrate.New(pool, "app"), os.Args[1:])
+//	}
+//
+//the applied version is recorded in migrations collection after the Up is done,
+//and the concurrent runner is guarded by the lock on migrations_lock collection.
+//the migration is run in transaction when the server supports it, the pool passed to Up/Down is bound to
+//the transaction session and the record is written in same transaction.
+//the migration having operation which is not allowed in transaction, like creating index or dropping collection,
+//should set NoTransaction, and its Up/Down should be idempotent for retrying after fail.
+//the runner is stopped after the running migration when the lock is lost, and the transaction of it is aborted.
+package migrate
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+	mongoc "gopkg.in/mongoc.v1"
+)
+
+//Func is the migration function.
+type Func func(pool *mongoc.Pool) error
+
+//Migration is the registered migration.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      Func
+	Down    Func //it can be nil when the migration is not reversible.
+	//NoTransaction will run the migration without transaction, it is required when the migration
+	//has operation which is not allowed in transaction.
+	NoTransaction bool
+}
+
+//Record is the applied migration record in migrations collection.
+type Record struct {
+	Version int64     `bson:"_id"`
+	Name    string    `bson:"name"`
+	Applied time.Time `bson:"applied"`
+}
+
+//Status is the migration status.
+type Status struct {
+	*Migration
+	Applied time.Time //it is zero when not applied.
+}
+
+const errNamespaceExists = 48
+
+var registered = map[int64]*Migration{}
+var registeredLck sync.Mutex
+
+//Register will register the migration to default list, it will panic when the version is registered.
+func Register(version int64, name string, up, down Func) {
+	registeredLck.Lock()
+	defer registeredLck.Unlock()
+	if _, ok := registered[version]; ok {
+		panic(fmt.Sprintf("migration version(%v) is registered", version))
+	}
+	registered[version] = &Migration{
+		Version: version,
+		Name:    name,
+		Up:      up,
+		Down:    down,
+	}
+}
+
+//Migrator is the runner of migrations.
+type Migrator struct {
+	Pool       *mongoc.Pool
+	C          *mongoc.Collection //the migrations collection.
+	Lock       *mongoc.Collection //the lock collection.
+	LockTTL    time.Duration      //the lock ttl, default is 1m.
+	Out        io.Writer          //the output of progress, default is os.Stdout.
+	Migrations []*Migration       //the migrations sorted by version.
+}
+
+//New will create the migrator on database with all registered migration.
+func New(pool *mongoc.Pool, dbname string) *Migrator {
+	registeredLck.Lock()
+	var migrations = []*Migration{}
+	for _, migration := range registered {
+		migrations = append(migrations, migration)
+	}
+	registeredLck.Unlock()
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return &Migrator{
+		Pool:       pool,
+		C:          pool.C(dbname, "migrations"),
+		Lock:       pool.C(dbname, "migrations_lock"),
+		LockTTL:    time.Minute,
+		Out:        os.Stdout,
+		Migrations: migrations,
+	}
+}
+
+//Status return the status of all migration.
+func (m *Migrator) Status() (status []*Status, err error) {
+	applied, err := m.applied()
+	if err != nil {
+		return
+	}
+	for _, migration := range m.Migrations {
+		var one = &Status{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			one.Applied = record.Applied
+		}
+		status = append(status, one)
+	}
+	return
+}
+
+func (m *Migrator) applied() (applied map[int64]*Record, err error) {
+	var records []*Record
+	err = m.C.Find(nil, nil, 0, 0, &records)
+	if err != nil {
+		return
+	}
+	applied = map[int64]*Record{}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return
+}
+
+//Up will apply all pending migration which version is not greater than target, zero target is all.
+//it only print the pending migration when dryRun is true.
+func (m *Migrator) Up(target int64, dryRun bool) (done []*Migration, err error) {
+	return m.locked(dryRun, func(apply applyFunc) (done []*Migration, err error) {
+		applied, err := m.applied()
+		if err != nil {
+			return
+		}
+		for _, migration := range m.Migrations {
+			if target > 0 && migration.Version > target {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			fmt.Fprintf(m.Out, "up %v %v\n", migration.Version, migration.Name)
+			if dryRun {
+				done = append(done, migration)
+				continue
+			}
+			err = apply(migration, func(pool *mongoc.Pool) (err error) {
+				err = migration.Up(pool)
+				if err != nil {
+					err = fmt.Errorf("migration %v up fail with %v", migration.Version, err)
+					return
+				}
+				err = pool.C(m.C.DbName, m.C.Name).Insert(&Record{
+					Version: migration.Version,
+					Name:    migration.Name,
+					Applied: time.Now(),
+				})
+				return
+			})
+			if err != nil {
+				return
+			}
+			done = append(done, migration)
+		}
+		return
+	})
+}
+
+//Down will revert the last steps applied migration.
+//it only print the reverting migration when dryRun is true.
+func (m *Migrator) Down(steps int, dryRun bool) (done []*Migration, err error) {
+	return m.locked(dryRun, func(apply applyFunc) (done []*Migration, err error) {
+		applied, err := m.applied()
+		if err != nil {
+			return
+		}
+		for i := len(m.Migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.Migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == nil {
+				err = fmt.Errorf("migration %v is not reversible", migration.Version)
+				return
+			}
+			fmt.Fprintf(m.Out, "down %v %v\n", migration.Version, migration.Name)
+			if dryRun {
+				done = append(done, migration)
+				continue
+			}
+			err = apply(migration, func(pool *mongoc.Pool) (err error) {
+				err = migration.Down(pool)
+				if err != nil {
+					err = fmt.Errorf("migration %v down fail with %v", migration.Version, err)
+					return
+				}
+				_, err = pool.C(m.C.DbName, m.C.Name).DeleteOne(bson.M{"_id": migration.Version}, nil)
+				return
+			})
+			if err != nil {
+				return
+			}
+			done = append(done, migration)
+		}
+		return
+	})
+}
+
+//applyFunc will run the call of migration, it is in transaction when supported and it fail when the lock is lost.
+type applyFunc func(migration *Migration, call Func) error
+
+//locked will run the call with the migration lock, the lock is skipped when dryRun is true.
+//when the lock is lost, the call is stopped before next migration and the running transaction is aborted,
+//and it return after the call is stopped.
+func (m *Migrator) locked(dryRun bool, call func(apply applyFunc) ([]*Migration, error)) (done []*Migration, err error) {
+	if dryRun {
+		return call(nil)
+	}
+	locker, err := m.Lock.NewLocker()
+	if err != nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	lease, err := locker.TryAcquire("migrate", fmt.Sprintf("%v-%v", hostname, os.Getpid()), m.LockTTL)
+	if err == mongoc.ErrLocked {
+		err = fmt.Errorf("migration is running by other")
+	}
+	if err != nil {
+		return
+	}
+	defer locker.Release(lease)
+	transaction, err := m.Pool.SupportTransaction()
+	if err != nil {
+		return
+	}
+	if transaction { //the collection can't be created in transaction before 4.4.
+		err = m.Pool.Execute(m.C.DbName, bson.M{"create": m.C.Name}, nil, &bson.M{})
+		if berr, ok := err.(*mongoc.BSONError); ok && berr.Code == errNamespaceExists {
+			err = nil
+		}
+		if err != nil {
+			return
+		}
+	}
+	var lost = make(chan struct{})
+	var apply = func(migration *Migration, call Func) error {
+		select {
+		case <-lost:
+			return fmt.Errorf("migration %v is stopped by lock lost", migration.Version)
+		default:
+		}
+		if !transaction || migration.NoTransaction {
+			return call(m.Pool)
+		}
+		return m.Pool.WithTransaction(func(pool *mongoc.Pool) (err error) {
+			err = call(pool)
+			select {
+			case <-lost:
+				if err == nil {
+					err = fmt.Errorf("migration %v is stopped by lock lost", migration.Version)
+				}
+			default:
+			}
+			return
+		})
+	}
+	var result = make(chan error, 1)
+	go func() {
+		var cerr error
+		done, cerr = call(apply)
+		result <- cerr
+	}()
+	select {
+	case err = <-result:
+	case lerr := <-lease.Lost:
+		close(lost)
+		<-result //wait the running migration is stopped before releasing the lock.
+		err = fmt.Errorf("migration lock is lost with %v", lerr)
+	}
+	return
+}
+
+//Run will run the migrator by command line args, following:
+//
+//	status              print the status of all migration.
+//	up [-dry-run] [target version]
+//	down [-dry-run] [steps], default steps is 1.
+func Run(m *Migrator, args []string) (err error) {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: status|up|down [-dry-run] [version|steps]")
+	}
+	var flags = flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(m.Out)
+	var dryRun = flags.Bool("dry-run", false, "print the migration without running")
+	err = flags.Parse(args[1:])
+	if err != nil {
+		return
+	}
+	var arg int64
+	if flags.NArg() > 0 {
+		arg, err = strconv.ParseInt(flags.Arg(0), 10, 64)
+		if err != nil {
+			return
+		}
+	}
+	switch args[0] {
+	case "status":
+		var status []*Status
+		status, err = m.Status()
+		for _, one := range status {
+			var applied = "pending"
+			if !one.Applied.IsZero() {
+				applied = one.Applied.Format(time.RFC3339)
+			}
+			fmt.Fprintf(m.Out, "%v\t%v\t%v\n", one.Version, applied, one.Name)
+		}
+	case "up":
+		_, err = m.Up(arg, *dryRun)
+	case "down":
+		if arg < 1 {
+			arg = 1
+		}
+		_, err = m.Down(int(arg), *dryRun)
+	default:
+		err = fmt.Errorf("unknown command(%v)", args[0])
+	}
+	return
+}
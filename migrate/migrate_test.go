@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+	mongoc "gopkg.in/mongoc.v1"
+)
+
+func TestRegister(t *testing.T) {
+	Register(2, "b", nil, nil)
+	Register(1, "a", nil, nil)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("not panic")
+			}
+		}()
+		Register(1, "a", nil, nil)
+	}()
+	pool := mongoc.NewPool("mongodb://loc.m:27017", 100, 1)
+	m := New(pool, "test")
+	if len(m.Migrations) != 2 || m.Migrations[0].Version != 1 || m.Migrations[1].Version != 2 {
+		t.Error("sort error")
+		return
+	}
+	for _, args := range [][]string{
+		{},
+		{"xx"},
+		{"up", "x"},
+		{"up", "-xx"},
+	} {
+		if err := Run(m, args); err == nil {
+			t.Errorf("%v not error", args)
+			return
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	pool := mongoc.NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_migrate")
+	col.Drop()
+	m := New(pool, "test")
+	m.C.Drop()
+	m.Lock.Drop()
+	out := bytes.NewBuffer(nil)
+	m.Out = out
+	var step = func(version int64, value interface{}) *Migration {
+		return &Migration{
+			Version: version,
+			Name:    fmt.Sprintf("step %v", version),
+			Up: func(pool *mongoc.Pool) error {
+				_, err := pool.C("test", "mongoc_migrate").Upsert(bson.M{"_id": version}, bson.M{"$set": bson.M{"v": value}})
+				return err
+			},
+			Down: func(pool *mongoc.Pool) error {
+				_, err := pool.C("test", "mongoc_migrate").DeleteOne(bson.M{"_id": version}, nil)
+				return err
+			},
+		}
+	}
+	m.Migrations = []*Migration{step(1, 1), step(2, 2), step(3, 3)}
+	//
+	//dry run
+	done, err := m.Up(0, true)
+	if err != nil || len(done) != 3 {
+		t.Errorf("done:%v,err:%v", done, err)
+		return
+	}
+	if count, _ := col.Count(nil, 0, 0); count != 0 {
+		t.Error("dry run is applied")
+		return
+	}
+	//
+	//up to target
+	done, err = m.Up(2, false)
+	if err != nil || len(done) != 2 {
+		t.Errorf("done:%v,err:%v", done, err)
+		return
+	}
+	status, err := m.Status()
+	if err != nil || len(status) != 3 || status[0].Applied.IsZero() || status[1].Applied.IsZero() || !status[2].Applied.IsZero() {
+		t.Errorf("status:%v,err:%v", status, err)
+		return
+	}
+	err = Run(m, []string{"up"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if count, _ := col.Count(nil, 0, 0); count != 3 {
+		t.Error("up error")
+		return
+	}
+	//
+	//down
+	err = Run(m, []string{"down", "-dry-run", "2"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if count, _ := col.Count(nil, 0, 0); count != 3 {
+		t.Error("dry run is applied")
+		return
+	}
+	err = Run(m, []string{"down", "2"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if count, _ := col.Count(nil, 0, 0); count != 1 {
+		t.Error("down error")
+		return
+	}
+	out.Reset()
+	err = Run(m, []string{"status"})
+	if err != nil || !strings.Contains(out.String(), "pending\tstep 3") {
+		t.Errorf("out:%v,err:%v", out.String(), err)
+		return
+	}
+	//
+	//error
+	m.Migrations = append(m.Migrations, &Migration{
+		Version: 4,
+		Name:    "fail",
+		Up: func(pool *mongoc.Pool) error {
+			return fmt.Errorf("fail")
+		},
+	})
+	_, err = m.Up(0, false)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	m.Migrations[0].Down = nil
+	_, err = m.Down(10, false)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	//
+	//rollback
+	if supported, _ := pool.SupportTransaction(); supported {
+		m.Migrations = []*Migration{step(1, 1), {
+			Version: 5,
+			Name:    "rollback",
+			Up: func(pool *mongoc.Pool) error {
+				_, err := pool.C("test", "mongoc_migrate").Upsert(bson.M{"_id": 5}, bson.M{"$set": bson.M{"v": 5}})
+				if err != nil {
+					return err
+				}
+				return fmt.Errorf("fail")
+			},
+		}}
+		_, err = m.Up(0, false)
+		if err == nil {
+			t.Error("not error")
+			return
+		}
+		if count, _ := col.Count(bson.M{"_id": 5}, 0, 0); count != 0 {
+			t.Error("not rollback")
+			return
+		}
+	}
+	//
+	//locked
+	locker, _ := m.Lock.NewLocker()
+	lease, err := locker.TryAcquire("migrate", "other", m.LockTTL)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer locker.Release(lease)
+	_, err = m.Up(0, false)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
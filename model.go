@@ -0,0 +1,472 @@
+package mongoc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//BeforeInserter is the hook called before the document is inserted by Repository.
+type BeforeInserter interface {
+	BeforeInsert() error
+}
+
+//BeforeUpdater is the hook called before the document is updated by Repository.
+type BeforeUpdater interface {
+	BeforeUpdate() error
+}
+
+//AfterFinder is the hook called after the document is found by Repository.
+type AfterFinder interface {
+	AfterFind() error
+}
+
+//Indexer is the interface to declare the indexes of model by method, it is merged with indexes declared by tag.
+type Indexer interface {
+	Indexes() []*Index
+}
+
+//Document is the base document to be embedded by inline, following:
+//
+//	type User struct {
+//		mongoc.Document `bson:",inline"`
+//		Name            string `bson:"name" mongoc:"index,unique"`
+//	}
+type Document struct {
+	ID      bson.ObjectId `bson:"_id,omitempty"`
+	Created time.Time     `bson:"created" mongoc:"created"`
+	Updated time.Time     `bson:"updated" mongoc:"updated"`
+}
+
+//Model is the registered metadata of struct, it is parsed by struct tag, following:
+//
+//	_ struct{}  `mongoc:"collection=users"` //the collection name, default is lower type name.
+//	A string    `mongoc:"index"`            //the single field index named a.
+//	B int       `mongoc:"index=b_c,desc"`   //the compound index named b_c by field order, desc is -b.
+//	C int       `mongoc:"index=b_c,unique"` //the unique/sparse/expire=seconds is applied to index.
+//	T time.Time `mongoc:"created"`          //the time set on inserting, also updated.
+//	V int       `mongoc:"version"`          //the version field used by Collection.UpdateVersioned.
+type Model struct {
+	Type    reflect.Type
+	Name    string //the collection name.
+	Indexes []*Index
+	//
+	id      []int
+	created []int
+	updated []int
+	version []int
+	//
+	createdName string
+	versionName string
+}
+
+//field return the field value by index path.
+func (m *Model) field(val reflect.Value, index []int) (field reflect.Value, ok bool) {
+	if index == nil {
+		return
+	}
+	return reflect.Indirect(val).FieldByIndex(index), true
+}
+
+//Registry is the model registry to map struct to collection.
+type Registry struct {
+	Pool   Poolable
+	DbName string
+	models map[reflect.Type]*Model
+	lck    sync.RWMutex
+}
+
+//NewRegistry will create the model registry on database.
+func NewRegistry(pool Poolable, dbname string) *Registry {
+	return &Registry{
+		Pool:   pool,
+		DbName: dbname,
+		models: map[reflect.Type]*Model{},
+	}
+}
+
+//C return the collection of model.
+func (r *Registry) C(model *Model) *Collection {
+	return &Collection{
+		Name:   model.Name,
+		DbName: r.DbName,
+		Pool:   r.Pool,
+	}
+}
+
+//Register will parse the model by struct tag and check the indexes on collection, the v is struct or pointer to struct.
+//the registered model is cached by type.
+func (r *Registry) Register(v interface{}) (model *Model, err error) {
+	var typ = reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		err = fmt.Errorf("the model must be struct, but %v", typ)
+		return
+	}
+	r.lck.RLock()
+	model = r.models[typ]
+	r.lck.RUnlock()
+	if model != nil {
+		return
+	}
+	model, err = ParseModel(typ)
+	if err != nil {
+		return
+	}
+	if indexer, ok := reflect.New(typ).Interface().(Indexer); ok {
+		model.Indexes = append(model.Indexes, indexer.Indexes()...)
+	}
+	if len(model.Indexes) > 0 {
+		err = r.C(model).CheckIndex(false, model.Indexes...)
+		if err != nil {
+			return
+		}
+	}
+	r.lck.Lock()
+	r.models[typ] = model
+	r.lck.Unlock()
+	return
+}
+
+//ParseModel will parse the model by struct tag.
+func ParseModel(typ reflect.Type) (model *Model, err error) {
+	model = &Model{
+		Type: typ,
+		Name: strings.ToLower(typ.Name()),
+	}
+	var indexes = map[string]*Index{}
+	err = parseModelFields(model, typ, nil, indexes)
+	return
+}
+
+func parseModelFields(model *Model, typ reflect.Type, parent []int, indexes map[string]*Index) (err error) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		index := append(append([]int{}, parent...), i)
+		name, inline, skip := FieldName(field)
+		tag := field.Tag.Get("mongoc")
+		if field.Name == "_" {
+			for _, item := range strings.Split(tag, ",") {
+				if strings.HasPrefix(item, "collection=") {
+					model.Name = strings.TrimPrefix(item, "collection=")
+				}
+			}
+			continue
+		}
+		if skip {
+			continue
+		}
+		if inline && field.Type.Kind() == reflect.Struct {
+			err = parseModelFields(model, field.Type, index, indexes)
+			if err != nil {
+				return
+			}
+			continue
+		}
+		if name == "_id" {
+			model.id = index
+		}
+		if len(tag) < 1 {
+			continue
+		}
+		var key = name
+		var indexName string
+		var indexOpts []string
+		for _, item := range strings.Split(tag, ",") {
+			switch {
+			case item == "created":
+				model.created, model.createdName = index, name
+			case item == "updated":
+				model.updated = index
+			case item == "version":
+				model.version, model.versionName = index, name
+			case item == "index":
+				indexName = name
+			case strings.HasPrefix(item, "index="):
+				indexName = strings.TrimPrefix(item, "index=")
+			case item == "desc":
+				key = "-" + name
+			case item == "unique" || item == "sparse" || strings.HasPrefix(item, "expire="):
+				indexOpts = append(indexOpts, item)
+			case len(item) > 0:
+				err = fmt.Errorf("unknown mongoc tag(%v) on %v.%v", item, typ.Name(), field.Name)
+				return
+			}
+		}
+		if len(indexName) < 1 {
+			if len(indexOpts) > 0 {
+				indexName = name
+			} else {
+				continue
+			}
+		}
+		having := indexes[indexName]
+		if having == nil {
+			having = &Index{Name: indexName}
+			indexes[indexName] = having
+			model.Indexes = append(model.Indexes, having)
+		}
+		having.Key = append(having.Key, key)
+		for _, opt := range indexOpts {
+			switch {
+			case opt == "unique":
+				having.Unique = true
+			case opt == "sparse":
+				having.Sparse = true
+			default:
+				having.ExpireAfterSeconds, err = strconv.Atoi(strings.TrimPrefix(opt, "expire="))
+				if err != nil {
+					err = fmt.Errorf("invalid expire tag(%v) on %v.%v", opt, typ.Name(), field.Name)
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+//FieldName return the bson field name of struct field by bson tag, the default name is lower field name.
+func FieldName(field reflect.StructField) (name string, inline, skip bool) {
+	if len(field.PkgPath) > 0 && !field.Anonymous { //unexported.
+		skip = true
+		return
+	}
+	tag := field.Tag.Get("bson")
+	if tag == "-" {
+		skip = true
+		return
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, flag := range parts[1:] {
+		if flag == "inline" {
+			inline = true
+		}
+	}
+	if len(name) < 1 {
+		name = strings.ToLower(field.Name)
+	}
+	return
+}
+
+//FieldPath return the bson field path by go field path like Address.City, the typ must be struct or pointer to struct.
+//the inline struct field is accessed directly like mgo.
+func FieldPath(typ reflect.Type, path string) (name string, err error) {
+	var names []string
+	for _, part := range strings.Split(path, ".") {
+		for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			typ = typ.Elem()
+		}
+		if typ == nil || typ.Kind() != reflect.Struct {
+			err = fmt.Errorf("field(%v) is not found on %v", path, typ)
+			return
+		}
+		var found bool
+		var fname string
+		typ, fname, found = lookupStructField(typ, part)
+		if !found {
+			err = fmt.Errorf("field(%v) is not found", path)
+			return
+		}
+		names = append(names, fname)
+	}
+	name = strings.Join(names, ".")
+	return
+}
+
+func lookupStructField(typ reflect.Type, goName string) (ftyp reflect.Type, name string, found bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fname, inline, skip := FieldName(field)
+		if skip {
+			continue
+		}
+		if inline && field.Type.Kind() == reflect.Struct {
+			ftyp, name, found = lookupStructField(field.Type, goName)
+			if found {
+				return
+			}
+			continue
+		}
+		if field.Name == goName {
+			return field.Type, fname, true
+		}
+	}
+	return
+}
+
+//Repository is the typed repository of model over collection, the T must be struct.
+type Repository[T any] struct {
+	Model *Model
+	C     *Collection
+}
+
+//NewRepository will register the model of T and create the repository.
+func NewRepository[T any](registry *Registry) (repo *Repository[T], err error) {
+	model, err := registry.Register((*T)(nil))
+	if err != nil {
+		return
+	}
+	repo = &Repository[T]{
+		Model: model,
+		C:     registry.C(model),
+	}
+	return
+}
+
+//FindByID will find the document by id, it return ErrNotFound when not found.
+func (r *Repository[T]) FindByID(id interface{}) (doc *T, err error) {
+	doc = new(T)
+	err = r.C.FindOne(bson.M{"_id": id}, nil, doc)
+	if err != nil {
+		doc = nil
+		return
+	}
+	err = afterFind(doc)
+	return
+}
+
+//Find will find the documents by query and sort keys, see ParseSorted.
+func (r *Repository[T]) Find(query interface{}, skip, limit int, sort ...string) (docs []*T, err error) {
+	var pipeline = NewPipeline().Match(query)
+	if len(sort) > 0 {
+		pipeline.Sort(sort...)
+	}
+	if skip > 0 {
+		pipeline.Skip(skip)
+	}
+	if limit > 0 {
+		pipeline.Limit(limit)
+	}
+	err = r.C.Aggregate(pipeline, nil, &docs)
+	if err != nil {
+		return
+	}
+	for _, doc := range docs {
+		err = afterFind(doc)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//Save will insert the document when id is empty, otherwise replace or insert it by id in one upsert,
+//the created of stored document is kept and read back to doc when it is replaced.
+//the id is generated when it is bson.ObjectId/string/interface{}, and the created/updated is set to now.
+//the BeforeInsert/BeforeUpdate hook is chosen by checking the document of id is exists before upsert,
+//so the BeforeInsert may be called on the document which is inserted by other concurrently.
+func (r *Repository[T]) Save(doc *T) (err error) {
+	var val = reflect.ValueOf(doc)
+	var now = time.Now()
+	id, ok := r.Model.field(val, r.Model.id)
+	if !ok {
+		err = fmt.Errorf("the _id field is not found on %v", r.Model.Type)
+		return
+	}
+	var exists = false
+	if !id.IsZero() {
+		var count int
+		count, err = r.C.CountDocuments(bson.M{"_id": id.Interface()}, &CountOptions{Limit: 1})
+		if err != nil {
+			return
+		}
+		exists = count > 0
+	}
+	if exists {
+		if hook, ok := interface{}(doc).(BeforeUpdater); ok {
+			err = hook.BeforeUpdate()
+		}
+	} else {
+		if hook, ok := interface{}(doc).(BeforeInserter); ok {
+			err = hook.BeforeInsert()
+		}
+	}
+	if err != nil {
+		return
+	}
+	created, hasCreated := r.Model.field(val, r.Model.created)
+	if !exists && hasCreated && created.IsZero() {
+		created.Set(reflect.ValueOf(now))
+	}
+	if updated, ok := r.Model.field(val, r.Model.updated); ok {
+		updated.Set(reflect.ValueOf(now))
+	}
+	if id.IsZero() { //the new id is not conflicted with other.
+		err = setNewID(id)
+		if err != nil {
+			return
+		}
+		err = r.C.Insert(doc)
+		return
+	}
+	var replacement interface{} = bson.M{"$literal": doc}
+	if hasCreated {
+		var fallback = created.Interface()
+		if created.IsZero() {
+			fallback = now
+		}
+		replacement = bson.M{
+			"$mergeObjects": []interface{}{
+				replacement,
+				bson.M{r.Model.createdName: bson.M{"$ifNull": []interface{}{"$" + r.Model.createdName, fallback}}},
+			},
+		}
+	}
+	var stored = bson.M{}
+	_, err = r.C.FindAndModify(bson.M{"_id": id.Interface()}, nil, []bson.M{{"$replaceWith": replacement}}, nil, true, true, &stored)
+	if err != nil {
+		return
+	}
+	if value, ok := stored[r.Model.createdName].(time.Time); hasCreated && ok {
+		created.Set(reflect.ValueOf(value))
+	}
+	return
+}
+
+//Delete will delete the document by id, it return ErrNotFound when not found.
+func (r *Repository[T]) Delete(doc *T) (err error) {
+	id, ok := r.Model.field(reflect.ValueOf(doc), r.Model.id)
+	if !ok {
+		err = fmt.Errorf("the _id field is not found on %v", r.Model.Type)
+		return
+	}
+	return r.DeleteByID(id.Interface())
+}
+
+//DeleteByID will delete the document by id, it return ErrNotFound when not found.
+func (r *Repository[T]) DeleteByID(id interface{}) (err error) {
+	result, err := r.C.DeleteOne(bson.M{"_id": id}, nil)
+	if err == nil && result.Deleted < 1 {
+		err = ErrNotFound
+	}
+	return
+}
+
+func afterFind(doc interface{}) (err error) {
+	if hook, ok := doc.(AfterFinder); ok {
+		err = hook.AfterFind()
+	}
+	return
+}
+
+//setNewID will set new object id to id field.
+func setNewID(id reflect.Value) (err error) {
+	var newID = bson.NewObjectId()
+	switch {
+	case id.Type() == reflect.TypeOf(newID) || id.Kind() == reflect.Interface:
+		id.Set(reflect.ValueOf(newID))
+	case id.Kind() == reflect.String:
+		id.SetString(newID.Hex())
+	default:
+		err = fmt.Errorf("the id is required for type %v", id.Type())
+	}
+	return
+}
@@ -0,0 +1,186 @@
+package mongoc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+type modelUser struct {
+	_        struct{} `mongoc:"collection=mongoc_user"`
+	Document `bson:",inline"`
+	Name     string    `bson:"name" mongoc:"index,unique"`
+	Age      int       `bson:"age" mongoc:"index=age_name,desc"`
+	Nick     string    `mongoc:"index=age_name"`
+	Login    time.Time `bson:"login" mongoc:"expire=3600"`
+	Hidden   string    `bson:"-" mongoc:"index"`
+	hooks    []string
+}
+
+func (m *modelUser) BeforeInsert() error {
+	if m.Name == "error" {
+		return fmt.Errorf("insert error")
+	}
+	m.hooks = append(m.hooks, "insert")
+	return nil
+}
+
+func (m *modelUser) BeforeUpdate() error {
+	if m.Name == "error" {
+		return fmt.Errorf("update error")
+	}
+	m.hooks = append(m.hooks, "update")
+	return nil
+}
+
+func (m *modelUser) AfterFind() error {
+	m.hooks = append(m.hooks, "find")
+	return nil
+}
+
+func (m *modelUser) Indexes() []*Index {
+	return []*Index{
+		{
+			Key:  []string{"created"},
+			Name: "created",
+		},
+	}
+}
+
+func TestParseModel(t *testing.T) {
+	model, err := ParseModel(reflect.TypeOf(modelUser{}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if model.Name != "mongoc_user" || len(model.Indexes) != 3 {
+		t.Errorf("model:%v", model)
+		return
+	}
+	var keys []string
+	for _, index := range model.Indexes {
+		keys = append(keys, fmt.Sprintf("%v:%v:%v:%v", index.Name, index.Key, index.Unique, index.ExpireAfterSeconds))
+	}
+	if fmt.Sprintf("%v", keys) != "[name:[name]:true:0 age_name:[-age nick]:false:0 login:[login]:false:3600]" {
+		t.Errorf("keys:%v", keys)
+		return
+	}
+	if fmt.Sprintf("%v %v %v", model.id, model.created, model.updated) != "[1 0] [1 1] [1 2]" {
+		t.Errorf("model:%v", model)
+		return
+	}
+	//
+	//error
+	_, err = ParseModel(reflect.TypeOf(struct {
+		A int `mongoc:"xx"`
+	}{}))
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = ParseModel(reflect.TypeOf(struct {
+		A int `mongoc:"expire=x"`
+	}{}))
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = NewRegistry(nil, "test").Register(1)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestRepository(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	pool.C("test", "mongoc_user").Drop()
+	registry := NewRegistry(pool, "test")
+	repo, err := NewRepository[modelUser](registry)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	indexes, err := repo.C.ListIndexes()
+	if err != nil || len(indexes) != 5 {
+		t.Errorf("indexes:%v,err:%v", indexes, err)
+		return
+	}
+	//
+	//insert
+	user := &modelUser{Name: "a", Age: 10}
+	err = repo.Save(user)
+	if err != nil || len(user.ID) < 1 || user.Created.IsZero() || user.Updated != user.Created || user.hooks[0] != "insert" {
+		t.Errorf("user:%v,err:%v", user, err)
+		return
+	}
+	err = repo.Save(&modelUser{Name: "a"})
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	//
+	//update
+	user.Age = 11
+	err = repo.Save(user)
+	if err != nil || user.hooks[1] != "update" || !user.Updated.After(user.Created) {
+		t.Errorf("user:%v,err:%v", user, err)
+		return
+	}
+	found, err := repo.FindByID(user.ID)
+	if err != nil || found.Age != 11 || found.hooks[0] != "find" {
+		t.Errorf("found:%v,err:%v", found, err)
+		return
+	}
+	repo.Save(&modelUser{Name: "b", Age: 20})
+	preset := &modelUser{Document: Document{ID: bson.NewObjectId()}, Name: "c", Age: 1}
+	err = repo.Save(preset)
+	if err != nil || preset.Created.IsZero() || len(preset.hooks) != 1 || preset.hooks[0] != "insert" {
+		t.Errorf("preset:%v,err:%v", preset, err)
+		return
+	}
+	replaced := &modelUser{Document: Document{ID: preset.ID}, Name: "c", Age: 2}
+	err = repo.Save(replaced)
+	if err != nil || !replaced.Created.Equal(preset.Created.Truncate(time.Millisecond)) || len(replaced.hooks) != 1 || replaced.hooks[0] != "update" {
+		t.Errorf("replaced:%v,err:%v", replaced, err)
+		return
+	}
+	users, err := repo.Find(bson.M{}, 0, 10, "-age")
+	if err != nil || len(users) != 3 || users[0].Name != "b" || users[1].hooks[0] != "find" {
+		t.Errorf("users:%v,err:%v", users, err)
+		return
+	}
+	//
+	//delete
+	err = repo.Delete(user)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = repo.Delete(user)
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	_, err = repo.FindByID(user.ID)
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	//
+	//hook error
+	err = repo.Save(&modelUser{Name: "error"})
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	user.Name = "error"
+	err = repo.Save(user)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
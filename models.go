@@ -0,0 +1,139 @@
+package mongoc
+
+import (
+	"fmt"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//WriteModel is the typed bulk write operation, it is implemented by
+//InsertOneModel/UpdateOneModel/UpdateManyModel/ReplaceOneModel/DeleteOneModel/DeleteManyModel.
+type WriteModel interface {
+	//ModelName return the model name, like insertOne/updateMany, it is used to serialize the model.
+	ModelName() string
+}
+
+//InsertOneModel is the write model to insert one document.
+type InsertOneModel struct {
+	Document interface{} `bson:"document"`
+}
+
+//ModelName return insertOne.
+func (m *InsertOneModel) ModelName() string {
+	return "insertOne"
+}
+
+//UpdateOneModel is the write model to update one document by filter,
+//the update can be update document or aggregation pipeline array.
+type UpdateOneModel struct {
+	Filter interface{}        `bson:"filter"`
+	Update interface{}        `bson:"update"`
+	Opts   *BulkUpdateOptions `bson:"opts,omitempty"`
+}
+
+//ModelName return updateOne.
+func (m *UpdateOneModel) ModelName() string {
+	return "updateOne"
+}
+
+//UpdateManyModel is the write model to update all document by filter,
+//the update can be update document or aggregation pipeline array.
+type UpdateManyModel struct {
+	Filter interface{}        `bson:"filter"`
+	Update interface{}        `bson:"update"`
+	Opts   *BulkUpdateOptions `bson:"opts,omitempty"`
+}
+
+//ModelName return updateMany.
+func (m *UpdateManyModel) ModelName() string {
+	return "updateMany"
+}
+
+//ReplaceOneModel is the write model to replace one document by filter.
+type ReplaceOneModel struct {
+	Filter      interface{}        `bson:"filter"`
+	Replacement interface{}        `bson:"replacement"`
+	Opts        *BulkUpdateOptions `bson:"opts,omitempty"`
+}
+
+//ModelName return replaceOne.
+func (m *ReplaceOneModel) ModelName() string {
+	return "replaceOne"
+}
+
+//DeleteOneModel is the write model to delete one document by filter.
+type DeleteOneModel struct {
+	Filter interface{}        `bson:"filter"`
+	Opts   *BulkRemoveOptions `bson:"opts,omitempty"`
+}
+
+//ModelName return deleteOne.
+func (m *DeleteOneModel) ModelName() string {
+	return "deleteOne"
+}
+
+//DeleteManyModel is the write model to delete all document by filter.
+type DeleteManyModel struct {
+	Filter interface{}        `bson:"filter"`
+	Opts   *BulkRemoveOptions `bson:"opts,omitempty"`
+}
+
+//ModelName return deleteMany.
+func (m *DeleteManyModel) ModelName() string {
+	return "deleteMany"
+}
+
+//newModel will create the empty write model by name.
+func newModel(name string) (model WriteModel, err error) {
+	switch name {
+	case "insertOne":
+		model = &InsertOneModel{}
+	case "updateOne":
+		model = &UpdateOneModel{}
+	case "updateMany":
+		model = &UpdateManyModel{}
+	case "replaceOne":
+		model = &ReplaceOneModel{}
+	case "deleteOne":
+		model = &DeleteOneModel{}
+	case "deleteMany":
+		model = &DeleteManyModel{}
+	default:
+		err = fmt.Errorf("unknown write model(%v)", name)
+	}
+	return
+}
+
+type modelDoc struct {
+	Name  string      `bson:"name"`
+	Model interface{} `bson:"model"`
+}
+
+type rawModelDoc struct {
+	Name  string   `bson:"name"`
+	Model bson.Raw `bson:"model"`
+}
+
+//MarshalModel will marshal the write model to bson bytes, it is used to queue the write model.
+func MarshalModel(model WriteModel) ([]byte, error) {
+	return bson.Marshal(&modelDoc{
+		Name:  model.ModelName(),
+		Model: model,
+	})
+}
+
+//UnmarshalModel will unmarshal the write model from bytes by MarshalModel.
+//the document in model will be unmarshaled as bson.M.
+func UnmarshalModel(data []byte) (model WriteModel, err error) {
+	var doc = &rawModelDoc{}
+	err = bson.Unmarshal(data, doc)
+	if err != nil {
+		return
+	}
+	model, err = newModel(doc.Name)
+	if err != nil {
+		return
+	}
+	err = doc.Model.Unmarshal(model)
+	return
+}
@@ -0,0 +1,54 @@
+package mongoc
+
+import (
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestModelSerialize(t *testing.T) {
+	models := []WriteModel{
+		&InsertOneModel{Document: bson.M{"a": 1}},
+		&UpdateOneModel{Filter: bson.M{"a": 1}, Update: bson.M{"$set": bson.M{"b": 1}}},
+		&UpdateManyModel{Filter: bson.M{"a": 1}, Update: []bson.M{{"$set": bson.M{"b": 1}}}, Opts: &BulkUpdateOptions{Upsert: true}},
+		&ReplaceOneModel{Filter: bson.M{"a": 1}, Replacement: bson.M{"b": 1}},
+		&DeleteOneModel{Filter: bson.M{"a": 1}, Opts: &BulkRemoveOptions{Hint: "a_1"}},
+		&DeleteManyModel{Filter: bson.M{"a": 1}},
+	}
+	for _, model := range models {
+		bys, err := MarshalModel(model)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		back, err := UnmarshalModel(bys)
+		if err != nil || back.ModelName() != model.ModelName() {
+			t.Errorf("%v,%v", back, err)
+			return
+		}
+	}
+	bys, _ := MarshalModel(models[2])
+	back, _ := UnmarshalModel(bys)
+	if m := back.(*UpdateManyModel); m.Opts == nil || !m.Opts.Upsert || m.Filter.(bson.M)["a"] != 1 {
+		t.Errorf("%v", back)
+		return
+	}
+	bys, _ = MarshalModel(models[4])
+	back, _ = UnmarshalModel(bys)
+	if m := back.(*DeleteOneModel); m.Opts == nil || m.Opts.Hint != "a_1" {
+		t.Errorf("%v", back)
+		return
+	}
+	//
+	_, err := UnmarshalModel([]byte{})
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	bys, _ = bson.Marshal(bson.M{"name": "xx", "model": bson.M{}})
+	_, err = UnmarshalModel(bys)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -164,6 +165,23 @@ func logHandler(logLevel C.mongoc_log_level_t, logDomain *C.char, message *C.cha
 //default is log.Printf("[level] domain:message")
 var LogHandler = DefaultLogHandler
 
+var queryLint int32
+
+//SetQueryLint will enable/disable explaining each find in background and logging warning by LogHandler when it is COLLSCAN or in-memory SORT,
+//it is used for checking missing index on staging, the find in session is not explained, it is safe to be called concurrently.
+func SetQueryLint(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&queryLint, value)
+}
+
+//QueryLint return true when the query lint is enabled by SetQueryLint.
+func QueryLint() bool {
+	return atomic.LoadInt32(&queryLint) == 1
+}
+
 //Log is the default Logger
 var Log = log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile)
 
@@ -356,10 +374,14 @@ func marshalRawBSON(val interface{}) (bval *C.bson_t, err error) {
 /**** pool ****/
 
 //parse cursor to value.
+//if val is nil, the cursor will be drained only, it is used for $out/$merge.
 func parseCursor(client *Client, cursor *C.mongoc_cursor_t, val interface{}) (err error) {
 	var doc *C.bson_t
 	targetVal := reflect.Indirect(reflect.ValueOf(val))
-	if targetVal.Kind() == reflect.Slice { //for multi element.
+	if val == nil { //for not result.
+		for C.mongoc_cursor_next(cursor, &doc) {
+		}
+	} else if targetVal.Kind() == reflect.Slice { //for multi element.
 		elemType := targetVal.Type().Elem()
 		newVal := targetVal
 		for C.mongoc_cursor_next(cursor, &doc) {
@@ -424,6 +446,7 @@ type Pool struct {
 	//
 	Timeout time.Duration
 	Err     ErrorFilter
+	bound   bool //the pool only has the session bound client.
 }
 
 //NewPool will create the pool by size.
@@ -460,6 +483,14 @@ func (p *Pool) Pop() *Client {
 	if p.closed {
 		panic("pool is closed")
 	}
+	if p.bound { //the bound client is never created, waiting is deadlock on nested operation.
+		select {
+		case found := <-p.pool:
+			return found
+		default:
+			panic("the session bound client is in use, the operation in session must not be nested or concurrent")
+		}
+	}
 	var tempDelay = 5 * time.Millisecond // how long to sleep on accept failure
 	for {
 		select {
@@ -551,6 +582,13 @@ func (p *Pool) Execute(dbname string, cmds, opts, v interface{}) (err error) {
 	return client.Execute(dbname, cmds, opts, v)
 }
 
+//Aggregate will run the database level aggregation, like $currentOp/$listLocalSessions.
+func (p *Pool) Aggregate(dbname string, pipeline, opts interface{}, val interface{}) (err error) {
+	client := p.Pop()
+	defer client.Close()
+	return client.Aggregate(dbname, pipeline, opts, val)
+}
+
 // //Command will query command on db.
 // func (p *Pool) Command(dbname string, query, fields interface{}, skip, limit int, v interface{}) (err error) {
 // 	return p.CommandWithFlags(dbname, QueryNone, query, fields, skip, limit, 100, v)
@@ -609,6 +647,7 @@ type Client struct {
 	cols      map[string]*rawCollection
 	colLck    sync.RWMutex
 	LastError error
+	session   *Session //the bound session by Pool.StartSession.
 }
 
 //newClient will create client by C.mongoc_client_new.
@@ -705,6 +744,10 @@ func (c *Client) Execute(dbname string, cmds, opts, v interface{}) (err error) {
 	if err != nil {
 		return
 	}
+	err = c.appendSession(rawOpts)
+	if err != nil {
+		return
+	}
 	var berr C.bson_error_t
 	var reply C.bson_t
 	if C.mongoc_client_read_write_command_with_opts(c.raw, cdbname, rawCmds, nil, rawOpts, &reply, &berr) {
@@ -727,6 +770,48 @@ func (c *Client) Execute(dbname string, cmds, opts, v interface{}) (err error) {
 	return
 }
 
+//Aggregate will run the database level aggregation, like $currentOp/$listLocalSessions.
+//the opts can be *AggregateOptions or other document.
+func (c *Client) Aggregate(dbname string, pipeline, opts interface{}, val interface{}) (err error) {
+	if c.raw == nil {
+		panic("raw client is nil")
+	}
+	cdbname := C.CString(dbname)
+	var db = C.mongoc_client_get_database(c.raw, cdbname)
+	var rawPipeline, rawOpts *C.bson_t
+	defer func() {
+		C.free(unsafe.Pointer(cdbname))
+		C.mongoc_database_destroy(db)
+		if rawPipeline != nil {
+			C.bson_destroy(rawPipeline)
+		}
+		if rawOpts != nil {
+			C.bson_destroy(rawOpts)
+		}
+	}()
+	rawPipeline, err = parseBSON(pipeline)
+	if err != nil {
+		return
+	}
+	if o, ok := opts.(*AggregateOptions); opts == nil || (ok && o == nil) {
+		opts = map[string]interface{}{}
+	}
+	rawOpts, err = parseBSON(opts)
+	if err != nil {
+		return
+	}
+	err = c.appendSession(rawOpts)
+	if err != nil {
+		return
+	}
+	{ //execute cursor
+		var cursor = C.mongoc_database_aggregate(db, rawPipeline, rawOpts, nil)
+		err = parseCursor(c, cursor, val)
+		C.mongoc_cursor_destroy(cursor)
+	}
+	return
+}
+
 // //Command will query command on db.
 // func (c *Client) Command(dbname string, query, fields interface{}, skip, limit int, v interface{}) (err error) {
 // 	return c.CommandWithFlags(dbname, QueryNone, query, fields, skip, limit, 100, v)
@@ -769,6 +854,14 @@ func (c *Client) Execute(dbname string, cmds, opts, v interface{}) (err error) {
 // 	return
 // }
 
+//appendSession will append the bound session to options when client is bound by session.
+func (c *Client) appendSession(opts *C.bson_t) (err error) {
+	if c.session != nil {
+		err = c.session.append(opts)
+	}
+	return
+}
+
 //Ping to database.
 func (c *Client) Ping(dbname string) (err error) {
 	reply := map[string]interface{}{}
@@ -807,6 +900,24 @@ type Collection struct {
 //Insert many document to database.
 func (c *Collection) Insert(docs ...interface{}) (err error) {
 	var client = c.Pool.Pop()
+	if client.session != nil { //the legacy insert is not session bound.
+		defer client.Close()
+		reply := &updateResultReply{}
+		err = client.Execute(c.DbName, bson.D{
+			{
+				Name:  "insert",
+				Value: c.Name,
+			},
+			{
+				Name:  "documents",
+				Value: docs,
+			},
+		}, nil, reply)
+		if err == nil && len(reply.Errors) > 0 {
+			err = reply.Errors
+		}
+		return
+	}
 	var col = client.rawCollection(c.DbName, c.Name)
 	var bdoc *C.bson_t
 	var bdocs []*C.bson_t
@@ -842,9 +953,10 @@ type Upserted struct {
 
 //WriteError is the command error.
 type WriteError struct {
-	Index   int    `bson:"index"`
-	Code    int    `bson:"code"`
-	Message string `bson:"errmsg"`
+	Index   int         `bson:"index"`
+	Code    int         `bson:"code"`
+	Message string      `bson:"errmsg"`
+	Op      interface{} `bson:"op,omitempty"` //the failed operation, it is the WriteModel on bulk.
 }
 
 //WriteErrors is the WriteError slice.
@@ -893,51 +1005,197 @@ func (c *Collection) Update(selector, update interface{}, upsert, many bool) (ch
 	return
 }
 
-//UpdateMany document to database
-func (c *Collection) UpdateMany(selector, update interface{}) (chnaged *Changed, err error) {
-	return c.Update(selector, update, false, true)
+//UpdateMany document to database by options, the opts can be nil.
+//the update must be started with update operator or be the pipeline.
+func (c *Collection) UpdateMany(selector, update interface{}, opts *UpdateOptions) (result *UpdateResult, err error) {
+	err = checkUpdate(update)
+	if err != nil {
+		return
+	}
+	return c.update(selector, update, true, opts)
+}
+
+//UpdateOne document to database by options, the opts can be nil.
+//the update must be started with update operator or be the pipeline,
+//it return ErrNotFound with the result when document not found and not upserted.
+func (c *Collection) UpdateOne(selector, update interface{}, opts *UpdateOptions) (result *UpdateResult, err error) {
+	err = checkUpdate(update)
+	if err != nil {
+		return
+	}
+	result, err = c.update(selector, update, false, opts)
+	if err == nil && result.Matched < 1 && result.Upserted < 1 {
+		err = ErrNotFound
+	}
+	return
 }
 
-//UpdateOne document to database, return ErrNotFound when document not found
-func (c *Collection) UpdateOne(selector, update interface{}) (err error) {
-	var changed *Changed
-	changed, err = c.Update(selector, update, false, false)
-	if err == nil && changed.Matched < 1 {
+//ReplaceOne will replace one document by selector, the opts can be nil.
+//the replacement must not contain update operator,
+//it return ErrNotFound with the result when document not found and not upserted.
+func (c *Collection) ReplaceOne(selector, replacement interface{}, opts *UpdateOptions) (result *UpdateResult, err error) {
+	err = checkReplacement(replacement)
+	if err != nil {
+		return
+	}
+	result, err = c.update(selector, replacement, false, opts)
+	if err == nil && result.Matched < 1 && result.Upserted < 1 {
 		err = ErrNotFound
 	}
 	return
 }
 
-//Remove document to database by single
-func (c *Collection) Remove(selector interface{}, single bool) (n int, err error) {
+//UpdateOptions is the options for UpdateOne/UpdateMany/ReplaceOne.
+//for more https://docs.mongodb.com/manual/reference/command/update/
+type UpdateOptions struct {
+	Upsert       bool          //insert new document when no document matched.
+	ArrayFilters []interface{} //the filters to determine which array elements to modify.
+	Collation    bson.M        //the collation used to match document.
+	Hint         interface{}   //the index name or index key document used to match document.
+	Let          bson.M        //the variables which can be accessed in update.
+}
+
+//UpdateResult is the result of UpdateOne/UpdateMany/ReplaceOne.
+type UpdateResult struct {
+	Matched    int         //the number of document matched.
+	Modified   int         //the number of document modified.
+	Upserted   int         //the number of document upserted.
+	UpsertedID interface{} //the upserted _id, it is nil when no document upserted.
+}
+
+type updateResultReply struct {
+	N         int         `bson:"n"`
+	NModified int         `bson:"nModified"`
+	Upserted  []*Upserted `bson:"upserted"`
+	Errors    WriteErrors `bson:"writeErrors"`
+}
+
+//update will execute the update command with one statement by options.
+func (c *Collection) update(selector, document interface{}, multi bool, opts *UpdateOptions) (result *UpdateResult, err error) {
 	var client = c.Pool.Pop()
 	defer client.Close()
 	if selector == nil {
 		selector = map[string]interface{}{}
 	}
-	var delete = bson.M{
-		"q": selector,
+	if opts == nil {
+		opts = &UpdateOptions{}
 	}
-	if single {
-		delete["limit"] = 1
-	} else {
-		delete["limit"] = 0
+	var statement = bson.D{
+		{
+			Name:  "q",
+			Value: selector,
+		},
+		{
+			Name:  "u",
+			Value: document,
+		},
+		{
+			Name:  "upsert",
+			Value: opts.Upsert,
+		},
+		{
+			Name:  "multi",
+			Value: multi,
+		},
 	}
-	var reply = bson.M{}
-	err = client.Execute(c.DbName, bson.D{
+	if len(opts.ArrayFilters) > 0 {
+		statement = append(statement, bson.DocElem{Name: "arrayFilters", Value: opts.ArrayFilters})
+	}
+	if opts.Collation != nil {
+		statement = append(statement, bson.DocElem{Name: "collation", Value: opts.Collation})
+	}
+	if opts.Hint != nil {
+		statement = append(statement, bson.DocElem{Name: "hint", Value: opts.Hint})
+	}
+	var command = bson.D{
 		{
-			Name:  "delete",
+			Name:  "update",
 			Value: c.Name,
 		},
 		{
-			Name: "deletes",
-			Value: []bson.M{
-				delete,
-			},
+			Name:  "updates",
+			Value: []bson.D{statement},
 		},
-	}, nil, &reply)
+	}
+	if opts.Let != nil {
+		command = append(command, bson.DocElem{Name: "let", Value: opts.Let})
+	}
+	reply := &updateResultReply{}
+	err = client.Execute(c.DbName, command, nil, reply)
+	if err != nil {
+		return
+	}
+	if len(reply.Errors) > 0 {
+		err = reply.Errors
+		return
+	}
+	result = &UpdateResult{
+		Matched:  reply.N - len(reply.Upserted),
+		Modified: reply.NModified,
+		Upserted: len(reply.Upserted),
+	}
+	if len(reply.Upserted) > 0 {
+		result.UpsertedID = reply.Upserted[0].ID
+	}
+	return
+}
+
+//checkReplacement will check the replacement document is not started with update operator.
+func checkReplacement(replacement interface{}) (err error) {
+	first, err := firstKey(replacement)
+	if err == nil && strings.HasPrefix(first, "$") {
+		err = fmt.Errorf("the replacement document must not contain update operator(%v)", first)
+	}
+	return
+}
+
+//checkUpdate will check the update document is started with update operator, the pipeline is not checked.
+func checkUpdate(update interface{}) (err error) {
+	if getter, ok := update.(bson.Getter); ok {
+		update, err = getter.GetBSON()
+		if err != nil {
+			return
+		}
+	}
+	if update != nil {
+		typ := reflect.TypeOf(update)
+		if (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem() != reflect.TypeOf(bson.DocElem{}) && typ.Elem().Kind() != reflect.Uint8 {
+			return //the update pipeline.
+		}
+	}
+	first, err := firstKey(update)
+	if err == nil && !strings.HasPrefix(first, "$") {
+		err = fmt.Errorf("the update document must be started with update operator, but (%v)", first)
+	}
+	return
+}
+
+//firstKey return the first key of document.
+func firstKey(document interface{}) (first string, err error) {
+	bys, ok := document.([]byte)
+	if !ok {
+		bys, err = bson.Marshal(document)
+		if err != nil {
+			return
+		}
+	}
+	var doc bson.D
+	err = bson.Unmarshal(bys, &doc)
+	if err == nil && len(doc) > 0 {
+		first = doc[0].Name
+	}
+	return
+}
+
+//Remove document to database by single
+func (c *Collection) Remove(selector interface{}, single bool) (n int, err error) {
+	var limit = 0
+	if single {
+		limit = 1
+	}
+	result, err := c.delete(selector, limit, nil)
 	if err == nil {
-		n = reply["n"].(int)
+		n = result.Deleted
 	}
 	return
 }
@@ -947,6 +1205,88 @@ func (c *Collection) RemoveAll(selector interface{}) (n int, err error) {
 	return c.Remove(selector, false)
 }
 
+//DeleteOne will delete one document by selector, the opts can be nil.
+func (c *Collection) DeleteOne(selector interface{}, opts *DeleteOptions) (result *DeleteResult, err error) {
+	return c.delete(selector, 1, opts)
+}
+
+//DeleteMany will delete all document by selector, the opts can be nil.
+func (c *Collection) DeleteMany(selector interface{}, opts *DeleteOptions) (result *DeleteResult, err error) {
+	return c.delete(selector, 0, opts)
+}
+
+//DeleteOptions is the options for DeleteOne/DeleteMany.
+//for more https://docs.mongodb.com/manual/reference/command/delete/
+type DeleteOptions struct {
+	Collation bson.M      //the collation used to match document.
+	Hint      interface{} //the index name or index key document used to match document.
+	Let       bson.M      //the variables which can be accessed in selector.
+}
+
+//DeleteResult is the result of DeleteOne/DeleteMany.
+type DeleteResult struct {
+	Deleted int //the number of document deleted.
+}
+
+type deleteResultReply struct {
+	N      int         `bson:"n"`
+	Errors WriteErrors `bson:"writeErrors"`
+}
+
+//delete will execute the delete command with one statement by options.
+func (c *Collection) delete(selector interface{}, limit int, opts *DeleteOptions) (result *DeleteResult, err error) {
+	var client = c.Pool.Pop()
+	defer client.Close()
+	if selector == nil {
+		selector = map[string]interface{}{}
+	}
+	if opts == nil {
+		opts = &DeleteOptions{}
+	}
+	var statement = bson.D{
+		{
+			Name:  "q",
+			Value: selector,
+		},
+		{
+			Name:  "limit",
+			Value: limit,
+		},
+	}
+	if opts.Collation != nil {
+		statement = append(statement, bson.DocElem{Name: "collation", Value: opts.Collation})
+	}
+	if opts.Hint != nil {
+		statement = append(statement, bson.DocElem{Name: "hint", Value: opts.Hint})
+	}
+	var command = bson.D{
+		{
+			Name:  "delete",
+			Value: c.Name,
+		},
+		{
+			Name:  "deletes",
+			Value: []bson.D{statement},
+		},
+	}
+	if opts.Let != nil {
+		command = append(command, bson.DocElem{Name: "let", Value: opts.Let})
+	}
+	reply := &deleteResultReply{}
+	err = client.Execute(c.DbName, command, nil, reply)
+	if err != nil {
+		return
+	}
+	if len(reply.Errors) > 0 {
+		err = reply.Errors
+		return
+	}
+	result = &DeleteResult{
+		Deleted: reply.N,
+	}
+	return
+}
+
 //Changed is the findAndModify reply info.
 type Changed struct {
 	Upserted interface{} `bson:"upserted"`  //the upsert id
@@ -1042,7 +1382,16 @@ func (c *Collection) Upsert(query, update interface{}) (changed *Changed, err er
 
 //FindWithFlags the document by flags.
 func (c *Collection) FindWithFlags(flags QueryFlags, query, fields interface{}, skip, limit, batchSize int, val interface{}) (err error) {
+	var bound = false
+	if QueryLint() { //run after client is pushed back.
+		defer func() {
+			if err == nil && !bound { //the session bound pool may be ended after find.
+				c.lintFindAsync(query, fields, skip, limit)
+			}
+		}()
+	}
 	var client = c.Pool.Pop() //apply client
+	bound = client.session != nil
 	var col = client.rawCollection(c.DbName, c.Name)
 	var rawQuery, rawFields *C.bson_t
 	defer func() {
@@ -1068,6 +1417,10 @@ func (c *Collection) FindWithFlags(flags QueryFlags, query, fields interface{},
 	if err != nil {
 		return
 	}
+	if client.session != nil { //the legacy find is not session bound, the flags is ignored.
+		err = client.findSession(col, query, fields, skip, limit, batchSize, val)
+		return
+	}
 	{ //execute cursor
 		var cursor = C.mongoc_collection_find(col.raw, C.mongoc_query_flags_t(flags),
 			C.uint32_t(skip), C.uint32_t(limit), C.uint32_t(batchSize), rawQuery, rawFields, nil)
@@ -1077,6 +1430,109 @@ func (c *Collection) FindWithFlags(flags QueryFlags, query, fields interface{},
 	return
 }
 
+//findSession will find the document by find command options in bound session, the legacy $query/$orderby is supported.
+func (c *Client) findSession(col *rawCollection, query, fields interface{}, skip, limit, batchSize int, val interface{}) (err error) {
+	var opts = bson.M{
+		"projection": fields,
+		"batchSize":  batchSize,
+	}
+	if skip > 0 {
+		opts["skip"] = skip
+	}
+	if limit > 0 {
+		opts["limit"] = limit
+	}
+	query, sort, err := legacyQuery(query)
+	if err != nil {
+		return
+	}
+	if sort != nil {
+		opts["sort"] = sort
+	}
+	cursor, err := c.findCursor(col, query, opts)
+	if err != nil {
+		return
+	}
+	err = parseCursor(c, cursor, val)
+	C.mongoc_cursor_destroy(cursor)
+	return
+}
+
+//legacyQuery will split the legacy {$query,$orderby} query to filter and sort, the query is returned as filter when it is not legacy.
+func legacyQuery(query interface{}) (filter, sort interface{}, err error) {
+	filter = query
+	var doc bson.D
+	switch q := query.(type) {
+	case nil:
+		return
+	case bson.M:
+		if q["$query"] != nil {
+			filter, sort = q["$query"], q["$orderby"]
+		}
+		return
+	case bson.D:
+		doc = q
+	default: //the raw value is kept for keeping the order of $orderby.
+		var bys []byte
+		bys, err = bson.Marshal(query)
+		if err != nil {
+			return
+		}
+		var raw bson.RawD
+		err = bson.Unmarshal(bys, &raw)
+		if err != nil {
+			return
+		}
+		for _, elem := range raw {
+			doc = append(doc, bson.DocElem{Name: elem.Name, Value: elem.Value})
+		}
+	}
+	var legacy = false
+	for _, elem := range doc {
+		switch elem.Name {
+		case "$query":
+			filter, legacy = elem.Value, true
+		case "$orderby":
+			sort = elem.Value
+		}
+	}
+	if !legacy {
+		filter, sort = query, nil
+	}
+	return
+}
+
+//findCursor will create the cursor by find options, the bound session is appended to options.
+//the cursor must be destroyed after used.
+func (c *Client) findCursor(col *rawCollection, filter, opts interface{}) (cursor *C.mongoc_cursor_t, err error) {
+	if filter == nil {
+		filter = map[string]interface{}{}
+	}
+	var rawFilter, rawOpts *C.bson_t
+	defer func() {
+		if rawFilter != nil {
+			C.bson_destroy(rawFilter)
+		}
+		if rawOpts != nil {
+			C.bson_destroy(rawOpts)
+		}
+	}()
+	rawFilter, err = parseBSON(filter)
+	if err != nil {
+		return
+	}
+	rawOpts, err = parseBSON(opts)
+	if err != nil {
+		return
+	}
+	err = c.appendSession(rawOpts)
+	if err != nil {
+		return
+	}
+	cursor = C.mongoc_collection_find_with_opts(col.raw, rawFilter, rawOpts, nil)
+	return
+}
+
 //Find the document by flags.
 func (c *Collection) Find(query, fields interface{}, skip, limit int, val interface{}) (err error) {
 	return c.FindWithFlags(QueryNone, query, fields, skip, limit, 100, val)
@@ -1117,6 +1573,10 @@ func (c *Collection) PipeWithFlags(flags QueryFlags, pipeline, opts interface{},
 	if err != nil {
 		return
 	}
+	err = client.appendSession(rawOpts)
+	if err != nil {
+		return
+	}
 	{ //execute cursor
 		var cursor = C.mongoc_collection_aggregate(col.raw, C.mongoc_query_flags_t(flags), rawPipeline, rawOpts, nil)
 		err = parseCursor(client, cursor, val)
@@ -1130,7 +1590,31 @@ func (c *Collection) Pipe(pipeline interface{}, val interface{}) (err error) {
 	return c.PipeWithFlags(QueryNone, pipeline, nil, val)
 }
 
+//AggregateOptions is the options for Aggregate.
+//for more https://docs.mongodb.com/manual/reference/command/aggregate/
+type AggregateOptions struct {
+	AllowDiskUse             bool        `bson:"allowDiskUse,omitempty"`
+	BatchSize                int         `bson:"batchSize,omitempty"`
+	MaxTimeMS                int         `bson:"maxTimeMS,omitempty"`
+	Collation                bson.M      `bson:"collation,omitempty"`
+	Hint                     interface{} `bson:"hint,omitempty"`
+	Comment                  interface{} `bson:"comment,omitempty"`
+	Let                      bson.M      `bson:"let,omitempty"`
+	BypassDocumentValidation bool        `bson:"bypassDocumentValidation,omitempty"`
+}
+
+//Aggregate the document by pipeline and options, the opts can be nil.
+//the pipeline can be *Pipeline or stage array, the val can be nil when pipeline is end with $out/$merge.
+func (c *Collection) Aggregate(pipeline interface{}, opts *AggregateOptions, val interface{}) (err error) {
+	if opts == nil {
+		opts = &AggregateOptions{}
+	}
+	return c.PipeWithFlags(QueryNone, pipeline, opts, val)
+}
+
 //CountWithFlags will return the row count by flags.
+//
+//Deprecated: it is using mongoc_collection_count, which is not accurate on sharded cluster, using CountDocuments instead.
 func (c *Collection) CountWithFlags(flags QueryFlags, query interface{}, skip, limit int) (count int, err error) {
 	var client = c.Pool.Pop()
 	var col = client.rawCollection(c.DbName, c.Name)
@@ -1148,6 +1632,10 @@ func (c *Collection) CountWithFlags(flags QueryFlags, query interface{}, skip, l
 	if err != nil {
 		return
 	}
+	if client.session != nil { //the legacy count is not session bound, the flags is ignored.
+		count, err = client.countSession(c.DbName, c.Name, query, skip, limit)
+		return
+	}
 	var berr C.bson_error_t
 	count = int(C.mongoc_collection_count(col.raw,
 		C.mongoc_query_flags_t(flags), rawQuery, C.int64_t(skip), C.int64_t(limit), nil, &berr))
@@ -1158,14 +1646,126 @@ func (c *Collection) CountWithFlags(flags QueryFlags, query interface{}, skip, l
 	return
 }
 
+//countSession will count the document by count command in bound session.
+func (c *Client) countSession(dbname, colname string, query interface{}, skip, limit int) (count int, err error) {
+	var cmd = bson.D{
+		{
+			Name:  "count",
+			Value: colname,
+		},
+		{
+			Name:  "query",
+			Value: query,
+		},
+	}
+	if skip > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "skip", Value: skip})
+	}
+	if limit > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "limit", Value: limit})
+	}
+	var reply = countReply{}
+	err = c.Execute(dbname, cmd, nil, &reply)
+	count = reply.N
+	return
+}
+
 //Count return the row coun.
 func (c *Collection) Count(query interface{}, skip, limit int) (count int, err error) {
 	return c.CountWithFlags(QueryNone, query, skip, limit)
 }
 
+//CountOptions is the options for CountDocuments.
+//for more https://docs.mongodb.com/manual/reference/method/db.collection.countDocuments/
+type CountOptions struct {
+	Skip      int         //the number of document to skip before counting.
+	Limit     int         //the max number of document to count.
+	Hint      interface{} //the index name or index key document used to match document.
+	Collation bson.M      //the collation used to match document.
+	MaxTimeMS int         //the max time in milliseconds to run the count.
+}
+
+type countReply struct {
+	N int `bson:"n"`
+}
+
+//CountDocuments will return the document count by filter, the opts can be nil.
+//it is executed by aggregation with $match/$group, so it is accurate on sharded cluster.
+func (c *Collection) CountDocuments(filter interface{}, opts *CountOptions) (count int, err error) {
+	if filter == nil {
+		filter = map[string]interface{}{}
+	}
+	if opts == nil {
+		opts = &CountOptions{}
+	}
+	var pipeline = []bson.M{
+		{
+			"$match": filter,
+		},
+	}
+	if opts.Skip > 0 {
+		pipeline = append(pipeline, bson.M{"$skip": opts.Skip})
+	}
+	if opts.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": opts.Limit})
+	}
+	pipeline = append(pipeline, bson.M{
+		"$group": bson.M{
+			"_id": 1,
+			"n": bson.M{
+				"$sum": 1,
+			},
+		},
+	})
+	var aggOpts = bson.M{}
+	if opts.Hint != nil {
+		aggOpts["hint"] = opts.Hint
+	}
+	if opts.Collation != nil {
+		aggOpts["collation"] = opts.Collation
+	}
+	if opts.MaxTimeMS > 0 {
+		aggOpts["maxTimeMS"] = opts.MaxTimeMS
+	}
+	var reply = countReply{}
+	err = c.PipeWithFlags(QueryNone, pipeline, aggOpts, &reply)
+	if err == ErrNotFound { //empty result when no document matched.
+		err = nil
+	}
+	count = reply.N
+	return
+}
+
+//EstimatedDocumentCount will return the estimated document count by collection metadata.
+//it is fast, but it may be not accurate after unclean shutdown or on sharded cluster with orphaned document.
+func (c *Collection) EstimatedDocumentCount() (count int, err error) {
+	var client = c.Pool.Pop()
+	defer client.Close()
+	var reply = countReply{}
+	err = client.Execute(c.DbName,
+		bson.D{
+			{
+				Name:  "count",
+				Value: c.Name,
+			},
+		}, nil, &reply)
+	count = reply.N
+	return
+}
+
 //Drop collection
 func (c *Collection) Drop() (err error) {
 	var client = c.Pool.Pop()
+	if client.session != nil { //the legacy drop is not session bound.
+		err = client.Execute(c.DbName, bson.D{
+			{
+				Name:  "drop",
+				Value: c.Name,
+			},
+		}, nil, &bson.M{})
+		client.Close()
+		return
+	}
 	var col = client.rawCollection(c.DbName, c.Name)
 	var berr C.bson_error_t
 	if !C.mongoc_collection_drop(col.raw, &berr) {
@@ -1217,6 +1817,24 @@ func (c *Collection) Drop() (err error) {
 //Rename the collection.
 func (c *Collection) Rename(dbName, newName string, dropTargeBeforeRename bool) (err error) {
 	var client = c.Pool.Pop()
+	if client.session != nil { //the legacy rename is not session bound.
+		err = client.Execute("admin", bson.D{
+			{
+				Name:  "renameCollection",
+				Value: c.DbName + "." + c.Name,
+			},
+			{
+				Name:  "to",
+				Value: dbName + "." + newName,
+			},
+			{
+				Name:  "dropTarget",
+				Value: dropTargeBeforeRename,
+			},
+		}, nil, &bson.M{})
+		client.Close()
+		return
+	}
 	var col = client.rawCollection(c.DbName, c.Name)
 	var berr C.bson_error_t
 	cDbName := C.CString(dbName)
@@ -1249,6 +1867,10 @@ func (c *Collection) Stats(options, v interface{}) (err error) {
 	if err != nil {
 		return
 	}
+	if client.session != nil { //the legacy stats is not session bound.
+		err = client.statsSession(c.DbName, c.Name, options, v)
+		return
+	}
 	var berr C.bson_error_t
 	var doc C.bson_t
 	if C.mongoc_collection_stats(col.raw, rawOptions, &doc, &berr) {
@@ -1263,6 +1885,25 @@ func (c *Collection) Stats(options, v interface{}) (err error) {
 	return
 }
 
+//statsSession will run collStats command with options in bound session.
+func (c *Client) statsSession(dbname, colname string, options, v interface{}) (err error) {
+	values, err := toDoc(options)
+	if err != nil {
+		return
+	}
+	var cmd = bson.D{
+		{
+			Name:  "collStats",
+			Value: colname,
+		},
+	}
+	for key, value := range values {
+		cmd = append(cmd, bson.DocElem{Name: key, Value: value})
+	}
+	err = c.Execute(dbname, cmd, nil, v)
+	return
+}
+
 type distinctReply struct {
 	Values interface{} `bson:"values"`
 	Ok     int         `bson:"ok"`
@@ -1298,7 +1939,7 @@ func (c *Collection) Distinct(key string, query, v interface{}) (err error) {
 //Index is the struct to create the mongodb index.
 //for more https://docs.mongodb.com/manual/reference/command/createIndexes/
 type Index struct {
-	Key                     []string       `bson:"-"`
+	Key                     []string       `bson:"-"` //the index keys by ParseSorted format, like -a, $2dsphere:loc.
 	RawKey                  bson.D         `bson:"key"`
 	Name                    string         `bson:"name"`
 	Background              bool           `bson:"background,omitempty"`
@@ -1438,183 +2079,405 @@ func (c *Collection) NewBulk(ordered bool) *Bulk {
 	}
 }
 
+//NewBulkWithOpts will create on bulk by bulk level options, the opts can be nil.
+//ordered:If the operations must be performed in order.
+func (c *Collection) NewBulkWithOpts(ordered bool, opts *BulkOptions) *Bulk {
+	return &Bulk{
+		C:       c,
+		Ordered: ordered,
+		Opts:    opts,
+	}
+}
+
+//BulkWriteOptions is the options for BulkWrite.
+type BulkWriteOptions struct {
+	BulkOptions
+	Unordered bool //if it is true, the operations is not performed in order, default is ordered.
+}
+
+//BulkWrite will execute multi write model as a single batch, the opts can be nil.
+//it returns the partial reply with *BulkWriteError when some write model is fail.
+func (c *Collection) BulkWrite(models []WriteModel, opts *BulkWriteOptions) (reply *BulkReply, err error) {
+	if opts == nil {
+		opts = &BulkWriteOptions{}
+	}
+	var bulk = c.NewBulkWithOpts(!opts.Unordered, &opts.BulkOptions)
+	bulk.Add(models...)
+	return bulk.Execute()
+}
+
+//WriteConcern is the write concern document.
+//for more https://docs.mongodb.com/manual/reference/write-concern/
+type WriteConcern struct {
+	W        interface{} `bson:"w,omitempty"` //the number of node or "majority".
+	J        bool        `bson:"j,omitempty"`
+	WTimeout int         `bson:"wtimeout,omitempty"`
+}
+
+//BulkOptions is the bulk level options.
+//the Comment/Let is required libmongoc 1.24 or later.
+type BulkOptions struct {
+	BypassDocumentValidation bool          `bson:"-"`
+	Comment                  interface{}   `bson:"comment,omitempty"`
+	Let                      bson.M        `bson:"let,omitempty"`
+	WriteConcern             *WriteConcern `bson:"writeConcern,omitempty"`
+}
+
+//BulkUpdateOptions is the operator level options for bulk update/replace.
+//the update document can be the aggregation pipeline array for update.
+type BulkUpdateOptions struct {
+	Upsert       bool          `bson:"upsert,omitempty"`
+	ArrayFilters []interface{} `bson:"arrayFilters,omitempty"`
+	Collation    bson.M        `bson:"collation,omitempty"`
+	Hint         interface{}   `bson:"hint,omitempty"`
+}
+
+//BulkRemoveOptions is the operator level options for bulk remove.
+type BulkRemoveOptions struct {
+	Collation bson.M      `bson:"collation,omitempty"`
+	Hint      interface{} `bson:"hint,omitempty"`
+}
+
 //BulkReply is bulk result.
 type BulkReply struct {
-	Opid     int
-	Inserted int           `bson:"nInserted"`
-	Modified int           `bson:"nModified"`
-	Matched  int           `bson:"nMatched"`
-	Removed  int           `bson:"nRemoved"`
-	Upserted int           `bson:"nUpserted"`
-	Errors   []interface{} `bson:"writeErrors"`
+	Opid              int
+	Inserted          int                 `bson:"nInserted"`
+	Modified          int                 `bson:"nModified"`
+	Matched           int                 `bson:"nMatched"`
+	Removed           int                 `bson:"nRemoved"`
+	Upserted          int                 `bson:"nUpserted"`
+	UpsertedIDs       map[int]interface{} `bson:"-"` //the upserted _id by operator index.
+	Errors            WriteErrors         `bson:"writeErrors"`
+	WriteConcernError *WriteConcernError  `bson:"-"`
+}
+
+type bulkReply struct {
+	BulkReply          `bson:",inline"`
+	UpsertedList       []*Upserted          `bson:"upserted"`
+	WriteConcernErrors []*WriteConcernError `bson:"writeConcernErrors"`
+}
+
+//WriteConcernError is the write concern error.
+type WriteConcernError struct {
+	Code    int    `bson:"code"`
+	Message string `bson:"errmsg"`
+	Info    bson.M `bson:"errInfo"`
 }
 
-//Operator is one bluk operator.
-type Operator struct {
-	Type   string        //the operator type
-	Values []interface{} //the operator arguments.
+func (w *WriteConcernError) Error() string {
+	return fmt.Sprintf("WriteConcernError(code:%v,message:%v)", w.Code, w.Message)
+}
+
+//BulkWriteError is the error of bulk execute when some operator is fail,
+//the partial reply is returned with it by Execute.
+type BulkWriteError struct {
+	Err               *BSONError         //the libmongoc error.
+	WriteErrors       WriteErrors        //the error of each failed operator.
+	WriteConcernError *WriteConcernError //the write concern error.
+}
+
+func (b *BulkWriteError) Error() string {
+	var msg = "BulkWriteError("
+	if b.Err != nil {
+		msg += b.Err.Error()
+	}
+	if len(b.WriteErrors) > 0 {
+		msg += ",writeErrors:" + b.WriteErrors.Error()
+	}
+	if b.WriteConcernError != nil {
+		msg += "," + b.WriteConcernError.Error()
+	}
+	return msg + ")"
+}
+
+//Unwrap return the libmongoc error.
+func (b *BulkWriteError) Unwrap() error {
+	if b.Err == nil {
+		return nil
+	}
+	return b.Err
 }
 
 //Bulk is wrapper of C.mongoc_bulk_t,
 //it provides an abstraction for submitting multiple write operations as a single batch.
 type Bulk struct {
-	Cmds    []*Operator
+	Cmds    []WriteModel
 	C       *Collection
 	Ordered bool
+	Opts    *BulkOptions
+}
+
+//Len return the count of write model in bulk.
+func (b *Bulk) Len() int {
+	return len(b.Cmds)
+}
+
+//Reset will clear all write model, the bulk can be reused after reset.
+func (b *Bulk) Reset() {
+	b.Cmds = nil
+}
+
+//Add will add multi write model to bulk.
+func (b *Bulk) Add(models ...WriteModel) {
+	b.Cmds = append(b.Cmds, models...)
 }
 
-//Insert is wrapper of C.mongoc_bulk_operation_insert(),
-//it will insert multi document to database by adding multi insert bulk operator.
+//Insert is wrapper of C.mongoc_bulk_operation_insert_with_opts(),
+//it will insert multi document to database by adding multi InsertOneModel.
 func (b *Bulk) Insert(docs ...interface{}) {
 	for _, doc := range docs {
-		b.Cmds = append(b.Cmds, &Operator{
-			Type:   "insert",
-			Values: []interface{}{doc},
+		b.Cmds = append(b.Cmds, &InsertOneModel{
+			Document: doc,
 		})
 	}
 }
 
-//Remove is wrapper of C.mongoc_bulk_operation_remove(),
+//Remove is wrapper of C.mongoc_bulk_operation_remove_many_with_opts(),
 //it will remove multi document from database by selector.
 func (b *Bulk) Remove(selector interface{}) {
-	b.Cmds = append(b.Cmds, &Operator{
-		Type:   "remove",
-		Values: []interface{}{selector},
-	})
+	b.RemoveWithOpts(selector, nil)
 }
 
-//RemoveOne is wrapper of C.mongoc_bulk_operation_remove_one(),
+//RemoveOne is wrapper of C.mongoc_bulk_operation_remove_one_with_opts(),
 //it will remove one document from database by selector.
 func (b *Bulk) RemoveOne(selector interface{}) {
-	b.Cmds = append(b.Cmds, &Operator{
-		Type:   "removeOne",
-		Values: []interface{}{selector},
-	})
+	b.RemoveOneWithOpts(selector, nil)
 }
 
-//Replace is wrapper of C.mongoc_bulk_operation_replace(),
+//Replace is wrapper of C.mongoc_bulk_operation_replace_one_with_opts(),
 //it will replace document from database by selector and new document.
 func (b *Bulk) Replace(selector, document interface{}, upsert bool) {
-	b.Cmds = append(b.Cmds, &Operator{
-		Type:   "replace",
-		Values: []interface{}{selector, document, upsert},
-	})
+	b.ReplaceWithOpts(selector, document, &BulkUpdateOptions{Upsert: upsert})
 }
 
-//Update is wrapper of C.mongoc_bulk_operation_update(),
+//Update is wrapper of C.mongoc_bulk_operation_update_many_with_opts(),
 //it will update multi document from database by selector and update options.
 func (b *Bulk) Update(selector, document interface{}, upsert bool) {
-	b.Cmds = append(b.Cmds, &Operator{
-		Type:   "update",
-		Values: []interface{}{selector, document, upsert},
-	})
+	b.UpdateWithOpts(selector, document, &BulkUpdateOptions{Upsert: upsert})
 }
 
-//UpdateOne is wrapper of C.mongoc_bulk_operation_update(),
+//UpdateOne is wrapper of C.mongoc_bulk_operation_update_one_with_opts(),
 //it will update one document from database by selector and update options.
 func (b *Bulk) UpdateOne(selector, document interface{}, upsert bool) {
-	b.Cmds = append(b.Cmds, &Operator{
-		Type:   "updateOne",
-		Values: []interface{}{selector, document, upsert},
+	b.UpdateOneWithOpts(selector, document, &BulkUpdateOptions{Upsert: upsert})
+}
+
+//RemoveWithOpts is wrapper of C.mongoc_bulk_operation_remove_many_with_opts(),
+//it will remove multi document from database by selector and options.
+func (b *Bulk) RemoveWithOpts(selector interface{}, opts *BulkRemoveOptions) {
+	b.Cmds = append(b.Cmds, &DeleteManyModel{
+		Filter: selector,
+		Opts:   opts,
+	})
+}
+
+//RemoveOneWithOpts is wrapper of C.mongoc_bulk_operation_remove_one_with_opts(),
+//it will remove one document from database by selector and options.
+func (b *Bulk) RemoveOneWithOpts(selector interface{}, opts *BulkRemoveOptions) {
+	b.Cmds = append(b.Cmds, &DeleteOneModel{
+		Filter: selector,
+		Opts:   opts,
+	})
+}
+
+//ReplaceWithOpts is wrapper of C.mongoc_bulk_operation_replace_one_with_opts(),
+//it will replace document from database by selector, new document and options.
+func (b *Bulk) ReplaceWithOpts(selector, document interface{}, opts *BulkUpdateOptions) {
+	b.Cmds = append(b.Cmds, &ReplaceOneModel{
+		Filter:      selector,
+		Replacement: document,
+		Opts:        opts,
+	})
+}
+
+//UpdateWithOpts is wrapper of C.mongoc_bulk_operation_update_many_with_opts(),
+//it will update multi document from database by selector, update document/pipeline and options.
+func (b *Bulk) UpdateWithOpts(selector, document interface{}, opts *BulkUpdateOptions) {
+	b.Cmds = append(b.Cmds, &UpdateManyModel{
+		Filter: selector,
+		Update: document,
+		Opts:   opts,
+	})
+}
+
+//UpdateOneWithOpts is wrapper of C.mongoc_bulk_operation_update_one_with_opts(),
+//it will update one document from database by selector, update document/pipeline and options.
+func (b *Bulk) UpdateOneWithOpts(selector, document interface{}, opts *BulkUpdateOptions) {
+	b.Cmds = append(b.Cmds, &UpdateOneModel{
+		Filter: selector,
+		Update: document,
+		Opts:   opts,
 	})
 }
 
+//parseBulk will create the raw bulk by ordered and bulk options.
+func (b *Bulk) parseBulk(client *Client, col *rawCollection) (rawBulk *C.mongoc_bulk_operation_t, err error) {
+	var opts = bson.M{}
+	if b.Opts != nil {
+		var bys []byte
+		bys, err = bson.Marshal(b.Opts)
+		if err != nil {
+			return
+		}
+		err = bson.Unmarshal(bys, &opts)
+		if err != nil {
+			return
+		}
+	}
+	opts["ordered"] = b.Ordered
+	rawOpts, err := parseBSON(opts)
+	if err != nil {
+		return
+	}
+	err = client.appendSession(rawOpts)
+	if err != nil {
+		C.bson_destroy(rawOpts)
+		return
+	}
+	rawBulk = C.mongoc_collection_create_bulk_operation_with_opts(col.raw, rawOpts)
+	C.bson_destroy(rawOpts)
+	if b.Opts != nil && b.Opts.BypassDocumentValidation {
+		C.mongoc_bulk_operation_set_bypass_document_validation(rawBulk, true)
+	}
+	return
+}
+
+//appendModel will append the write model to raw bulk.
+func appendModel(rawBluk *C.mongoc_bulk_operation_t, model WriteModel) (err error) {
+	var docs []interface{}
+	var opts interface{} = bson.M{}
+	var call func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool
+	switch m := model.(type) {
+	case *InsertOneModel:
+		docs = []interface{}{m.Document}
+		call = func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool {
+			return C.mongoc_bulk_operation_insert_with_opts(rawBluk, raws[0], rawOpts, berr)
+		}
+	case *UpdateOneModel:
+		docs = []interface{}{m.Filter, m.Update}
+		if m.Opts != nil {
+			opts = m.Opts
+		}
+		call = func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool {
+			return C.mongoc_bulk_operation_update_one_with_opts(rawBluk, raws[0], raws[1], rawOpts, berr)
+		}
+	case *UpdateManyModel:
+		docs = []interface{}{m.Filter, m.Update}
+		if m.Opts != nil {
+			opts = m.Opts
+		}
+		call = func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool {
+			return C.mongoc_bulk_operation_update_many_with_opts(rawBluk, raws[0], raws[1], rawOpts, berr)
+		}
+	case *ReplaceOneModel:
+		docs = []interface{}{m.Filter, m.Replacement}
+		if m.Opts != nil {
+			opts = m.Opts
+		}
+		call = func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool {
+			return C.mongoc_bulk_operation_replace_one_with_opts(rawBluk, raws[0], raws[1], rawOpts, berr)
+		}
+	case *DeleteOneModel:
+		docs = []interface{}{m.Filter}
+		if m.Opts != nil {
+			opts = m.Opts
+		}
+		call = func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool {
+			return C.mongoc_bulk_operation_remove_one_with_opts(rawBluk, raws[0], rawOpts, berr)
+		}
+	case *DeleteManyModel:
+		docs = []interface{}{m.Filter}
+		if m.Opts != nil {
+			opts = m.Opts
+		}
+		call = func(raws []*C.bson_t, rawOpts *C.bson_t, berr *C.bson_error_t) C.bool {
+			return C.mongoc_bulk_operation_remove_many_with_opts(rawBluk, raws[0], rawOpts, berr)
+		}
+	default:
+		return fmt.Errorf("unknown write model(%T)", model)
+	}
+	var raws []*C.bson_t
+	defer func() {
+		for _, raw := range raws {
+			C.bson_destroy(raw)
+		}
+	}()
+	for _, doc := range append(docs, opts) {
+		raw, terr := parseBSON(doc)
+		if terr != nil {
+			return terr
+		}
+		raws = append(raws, raw)
+	}
+	var berr C.bson_error_t
+	if !call(raws, raws[len(raws)-1], &berr) {
+		err = parseBSONError(&berr)
+	}
+	return
+}
+
 //Execute is wrapper of C.mongoc_bulk_operation_execute(),
 //it will commit all execute to database.
 func (b *Bulk) Execute() (reply *BulkReply, err error) {
 	var client = b.C.Pool.Pop()
 	var col = client.rawCollection(b.C.DbName, b.C.Name)
-	var rawBluk = C.mongoc_collection_create_bulk_operation(col.raw, C.bool(b.Ordered), nil)
-	defer func() {
-		client.Close()
-		C.mongoc_bulk_operation_destroy(rawBluk)
-	}()
+	defer client.Close()
+	rawBluk, err := b.parseBulk(client, col)
+	if err != nil {
+		return
+	}
+	defer C.mongoc_bulk_operation_destroy(rawBluk)
 	for _, cmd := range b.Cmds {
-		switch cmd.Type {
-		case "insert":
-			rawDoc, terr := parseBSON(cmd.Values[0])
-			if terr != nil {
-				err = terr
-				return
-			}
-			C.mongoc_bulk_operation_insert(rawBluk, rawDoc)
-			C.bson_destroy(rawDoc)
-		case "remove":
-			rawSelector, terr := parseBSON(cmd.Values[0])
-			if terr != nil {
-				err = terr
-				return
-			}
-			C.mongoc_bulk_operation_remove(rawBluk, rawSelector)
-			C.bson_destroy(rawSelector)
-		case "removeOne":
-			rawSelector, terr := parseBSON(cmd.Values[0])
-			if terr != nil {
-				err = terr
-				return
-			}
-			C.mongoc_bulk_operation_remove_one(rawBluk, rawSelector)
-			C.bson_destroy(rawSelector)
-		case "replace":
-			rawSelector, terr := parseBSON(cmd.Values[0])
-			if terr != nil {
-				err = terr
-				return
-			}
-			rawDoc, terr := parseBSON(cmd.Values[1])
-			if terr != nil {
-				err = terr
-				return
-			}
-			upsert := (cmd.Values[2]).(bool)
-			C.mongoc_bulk_operation_replace_one(rawBluk, rawSelector, rawDoc, C.bool(upsert))
-			C.bson_destroy(rawSelector)
-			C.bson_destroy(rawDoc)
-		case "update":
-			rawSelector, terr := parseBSON(cmd.Values[0])
-			if terr != nil {
-				err = terr
-				return
-			}
-			rawDoc, terr := parseBSON(cmd.Values[1])
-			if terr != nil {
-				err = terr
-				return
-			}
-			upsert := (cmd.Values[2]).(bool)
-			C.mongoc_bulk_operation_update(rawBluk, rawSelector, rawDoc, C.bool(upsert))
-			C.bson_destroy(rawSelector)
-			C.bson_destroy(rawDoc)
-		case "updateOne":
-			rawSelector, terr := parseBSON(cmd.Values[0])
-			if terr != nil {
-				err = terr
-				return
-			}
-			rawDoc, terr := parseBSON(cmd.Values[1])
-			if terr != nil {
-				err = terr
-				return
-			}
-			upsert := (cmd.Values[2]).(bool)
-			C.mongoc_bulk_operation_update_one(rawBluk, rawSelector, rawDoc, C.bool(upsert))
-			C.bson_destroy(rawSelector)
-			C.bson_destroy(rawDoc)
+		err = appendModel(rawBluk, cmd)
+		if err != nil {
+			return
 		}
 	}
 	var breply C.bson_t
 	var berr C.bson_error_t
 	var opid = int(C.mongoc_bulk_operation_execute(rawBluk, &breply, &berr))
+	var str = C.bson_get_data(&breply)
+	mbys := C.GoBytes(unsafe.Pointer(str), C.int(breply.len))
+	C.bson_destroy(&breply)
 	if opid < 1 {
-		err = parseBSONError(&berr)
-		client.LastError = err
+		var bsonErr = parseBSONError(&berr)
+		client.LastError = bsonErr
+		reply, err = b.parseReply(mbys, opid)
+		if err == nil && (len(reply.Errors) > 0 || reply.WriteConcernError != nil) {
+			err = &BulkWriteError{
+				Err:               bsonErr,
+				WriteErrors:       reply.Errors,
+				WriteConcernError: reply.WriteConcernError,
+			}
+		} else {
+			reply, err = nil, bsonErr
+		}
 	} else {
-		var str = C.bson_get_data(&breply)
-		mbys := C.GoBytes(unsafe.Pointer(str), C.int(breply.len))
-		reply = &BulkReply{}
-		err = bson.Unmarshal(mbys, reply)
-		reply.Opid = opid
+		reply, err = b.parseReply(mbys, opid)
+	}
+	return
+}
+
+//parseReply will parse the bulk reply and fill the upserted id/failed write model.
+func (b *Bulk) parseReply(mbys []byte, opid int) (reply *BulkReply, err error) {
+	var raw = &bulkReply{}
+	err = bson.Unmarshal(mbys, raw)
+	if err != nil {
+		return
+	}
+	reply = &raw.BulkReply
+	reply.Opid = opid
+	reply.UpsertedIDs = map[int]interface{}{}
+	for _, upserted := range raw.UpsertedList {
+		reply.UpsertedIDs[upserted.Index] = upserted.ID
+	}
+	for _, werr := range reply.Errors {
+		if werr.Op == nil && werr.Index >= 0 && werr.Index < len(b.Cmds) {
+			werr.Op = b.Cmds[werr.Index]
+		}
+	}
+	if len(raw.WriteConcernErrors) > 0 {
+		reply.WriteConcernError = raw.WriteConcernErrors[0]
 	}
-	C.bson_destroy(&breply)
 	return
 }
@@ -107,14 +107,14 @@ func TestMongoc(t *testing.T) {
 	}
 	//update
 	//
-	changed, err := col.UpdateMany(bson.M{
+	updated, err := col.UpdateMany(bson.M{
 		"b": 1,
 	}, bson.M{
 		"$set": bson.M{
 			"b": 101,
 		},
-	})
-	if err != nil || changed.Updated != 3 {
+	}, nil)
+	if err != nil || updated.Matched != 3 || updated.Modified != 3 {
 		t.Error(err)
 		return
 	}
@@ -126,14 +126,14 @@ func TestMongoc(t *testing.T) {
 		return
 	}
 	//
-	err = col.UpdateOne(bson.M{
+	updated, err = col.UpdateOne(bson.M{
 		"b": 101,
 	}, bson.M{
 		"$set": bson.M{
 			"b": 100,
 		},
-	})
-	if err != nil {
+	}, nil)
+	if err != nil || updated.Matched != 1 || updated.Modified != 1 {
 		t.Error(err)
 		return
 	}
@@ -147,7 +147,7 @@ func TestMongoc(t *testing.T) {
 	//find and modify
 	//
 	one = map[string]interface{}{}
-	changed, err = col.FindAndModify(
+	changed, err := col.FindAndModify(
 		bson.M{
 			"b": 2,
 		},
@@ -298,6 +298,221 @@ func TestMongoc(t *testing.T) {
 	pool.Close()
 }
 
+func TestCRUD(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	_, err := col.DeleteMany(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for i := 0; i < 5; i++ {
+		err = col.Insert(bson.M{
+			"_id":  fmt.Sprintf("crud-%v", i),
+			"a":    i,
+			"tags": []bson.M{{"v": 1}, {"v": 2}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	//update with array filters.
+	updated, err := col.UpdateMany(nil, bson.M{
+		"$set": bson.M{
+			"tags.$[x].v": 100,
+		},
+	}, &UpdateOptions{
+		ArrayFilters: []interface{}{bson.M{"x.v": 2}},
+	})
+	if err != nil || updated.Matched != 5 || updated.Modified != 5 {
+		t.Errorf("%v,%v", updated, err)
+		return
+	}
+	//upsert
+	updated, err = col.UpdateOne(bson.M{"_id": "crud-x"}, bson.M{
+		"$set": bson.M{
+			"a": 100,
+		},
+	}, &UpdateOptions{Upsert: true})
+	if err != nil || updated.Matched != 0 || updated.Upserted != 1 || updated.UpsertedID != "crud-x" {
+		t.Errorf("%v,%v", updated, err)
+		return
+	}
+	//replace
+	updated, err = col.ReplaceOne(bson.M{"_id": "crud-x"}, bson.M{"a": 200}, nil)
+	if err != nil || updated.Matched != 1 || updated.Modified != 1 {
+		t.Errorf("%v,%v", updated, err)
+		return
+	}
+	_, err = col.ReplaceOne(bson.M{"_id": "crud-x"}, bson.M{"$set": bson.M{"a": 300}}, nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = col.ReplaceOne(bson.M{"_id": "crud-none"}, bson.M{"a": 300}, nil)
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	//delete
+	deleted, err := col.DeleteOne(bson.M{"a": bson.M{"$lt": 3}}, &DeleteOptions{
+		Collation: bson.M{"locale": "en"},
+	})
+	if err != nil || deleted.Deleted != 1 {
+		t.Errorf("%v,%v", deleted, err)
+		return
+	}
+	deleted, err = col.DeleteMany(bson.M{"a": bson.M{"$lt": 3}}, &DeleteOptions{
+		Hint: bson.M{"_id": 1},
+	})
+	if err != nil || deleted.Deleted != 2 {
+		t.Errorf("%v,%v", deleted, err)
+		return
+	}
+	count, err := col.Count(nil, 0, 0)
+	if err != nil || count != 3 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+}
+
+func TestCountDocuments(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	_, err := col.DeleteMany(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	count, err := col.CountDocuments(nil, nil)
+	if err != nil || count != 0 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	for i := 0; i < 10; i++ {
+		err = col.Insert(bson.M{
+			"a": i,
+			"b": i % 3,
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	count, err = col.CountDocuments(bson.M{"b": 1}, nil)
+	if err != nil || count != 3 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	count, err = col.CountDocuments(nil, &CountOptions{
+		Skip:      2,
+		Limit:     5,
+		Hint:      bson.M{"_id": 1},
+		MaxTimeMS: 1000,
+	})
+	if err != nil || count != 5 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	count, err = col.EstimatedDocumentCount()
+	if err != nil || count != 10 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	_, err = col.CountDocuments(TestCountDocuments, nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	_, err := col.DeleteMany(nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for i := 0; i < 10; i++ {
+		err = col.Insert(bson.M{
+			"a": i,
+			"b": i % 3,
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	var res = []bson.M{}
+	err = col.Aggregate(
+		NewPipeline().
+			Match(bson.M{"a": bson.M{"$gte": 3}}).
+			Group("$b", bson.M{"n": bson.M{"$sum": 1}}).
+			Sort("_id"),
+		&AggregateOptions{
+			AllowDiskUse: true,
+			BatchSize:    2,
+			MaxTimeMS:    1000,
+			Comment:      "testing",
+		}, &res)
+	if err != nil || len(res) != 3 {
+		t.Errorf("aggregate fail %v err:%v", res, err)
+		return
+	}
+	//
+	err = col.Aggregate(NewPipeline().Match(bson.M{"b": 1}).Out("mongoc_out"), nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	count, err := pool.C("test", "mongoc_out").CountDocuments(nil, nil)
+	if err != nil || count != 3 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	err = col.Aggregate(NewPipeline().Match(bson.M{"b": 2}).Merge("mongoc_out", nil, nil, ""), nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	count, err = pool.C("test", "mongoc_out").CountDocuments(nil, nil)
+	if err != nil || count != 6 {
+		t.Errorf("count fail %v err:%v", count, err)
+		return
+	}
+	//
+	res = []bson.M{}
+	err = pool.Aggregate("admin", NewPipeline().CurrentOp(nil), nil, &res)
+	if err != nil || len(res) < 1 {
+		t.Errorf("aggregate fail %v err:%v", res, err)
+		return
+	}
+	res = []bson.M{}
+	err = pool.Aggregate("test", NewPipeline().ListLocalSessions(nil), &AggregateOptions{}, &res)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = pool.Aggregate("test", TestAggregate, nil, &res)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestCheckReplacement(t *testing.T) {
+	if checkReplacement(bson.M{"a": 1}) != nil || checkReplacement(bson.D{}) != nil {
+		t.Error("error")
+		return
+	}
+	if checkReplacement(bson.M{"$set": bson.M{"a": 1}}) == nil || checkReplacement(TestCheckReplacement) == nil {
+		t.Error("not error")
+		return
+	}
+}
+
 func TestErrorFilter(t *testing.T) {
 	ef := &DefaultErrorFilter{}
 	if !ef.IsNormalError(nil) || ef.IsNormalError(fmt.Errorf("error")) || ef.IsNormalError(&BSONError{}) ||
@@ -610,11 +825,28 @@ func TestErrCase(t *testing.T) {
 			return
 		}
 		//
-		err = col.UpdateOne(bson.M{"xx": "not found"}, bson.M{"xx": 1})
+		_, err = col.UpdateOne(bson.M{"xx": "not found"}, bson.M{"$set": bson.M{"xx": 1}}, nil)
 		if err != ErrNotFound {
 			t.Error("not error")
 			return
 		}
+		for _, update := range []interface{}{bson.M{"xx": 1}, bson.M{}, nil, TestErrCase} {
+			_, err = col.UpdateOne(nil, update, nil)
+			if err == nil || err == ErrNotFound {
+				t.Errorf("%v not error", update)
+				return
+			}
+			_, err = col.UpdateMany(nil, update, nil)
+			if err == nil {
+				t.Errorf("%v not error", update)
+				return
+			}
+		}
+		_, err = col.UpdateMany(bson.M{"xx": "not found"}, []bson.M{{"$set": bson.M{"xx": 1}}}, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
 		_, err = col.Update(nil, TestErrCase, true, true)
 		if err == nil {
 			t.Error("not error")
@@ -1018,6 +1250,30 @@ func TestBulk(t *testing.T) {
 		return
 	}
 	//
+	//test upserted and partial error
+	//
+	bulk = col.NewBulk(false)
+	bulk.Insert(bson.M{"_id": "bulk-1"})
+	bulk.Update(bson.M{"_id": "bulk-2"}, bson.M{"$set": bson.M{"a": 1}}, true)
+	bulk.Insert(bson.M{"_id": "bulk-1"})
+	bulk.UpdateOne(bson.M{"_id": "bulk-3"}, bson.M{"$set": bson.M{"a": 1}}, true)
+	reply, err = bulk.Execute()
+	berr, ok := err.(*BulkWriteError)
+	if !ok || reply == nil {
+		t.Errorf("%v,%v", reply, err)
+		return
+	}
+	if len(berr.WriteErrors) != 1 || berr.WriteErrors[0].Index != 2 ||
+		berr.WriteErrors[0].Op != bulk.Cmds[2] || berr.Err == nil || berr.Err.Code != ErrDuplicateKey {
+		t.Error(berr)
+		return
+	}
+	if reply.Inserted != 1 || reply.Upserted != 2 || len(reply.Errors) != 1 ||
+		reply.UpsertedIDs[1] != "bulk-2" || reply.UpsertedIDs[3] != "bulk-3" {
+		t.Error(reply)
+		return
+	}
+	//
 	//test bulk error
 	//
 	bulk = col.NewBulk(true)
@@ -1098,6 +1354,128 @@ func TestBulk(t *testing.T) {
 	}
 }
 
+func TestBulkWithOpts(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	bulk := col.NewBulkWithOpts(true, &BulkOptions{
+		BypassDocumentValidation: true,
+		Comment:                  "testing",
+		WriteConcern:             &WriteConcern{W: 1, WTimeout: 1000},
+	})
+	for i := 0; i < 5; i++ {
+		bulk.Insert(bson.M{
+			"_id":  fmt.Sprintf("opts-%v", i),
+			"name": fmt.Sprintf("Name%v", i),
+			"tags": []bson.M{{"v": 1}, {"v": 2}},
+		})
+	}
+	bulk.UpdateWithOpts(bson.M{}, bson.M{ //update all tags.v:2
+		"$set": bson.M{
+			"tags.$[x].v": 100,
+		},
+	}, &BulkUpdateOptions{
+		ArrayFilters: []interface{}{bson.M{"x.v": 2}},
+	})
+	bulk.UpdateOneWithOpts(bson.M{"name": "name1"}, []bson.M{ //update pipeline with collation.
+		{
+			"$set": bson.M{
+				"lower": bson.M{"$toLower": "$name"},
+			},
+		},
+	}, &BulkUpdateOptions{
+		Collation: bson.M{"locale": "en", "strength": 2},
+	})
+	bulk.ReplaceWithOpts(bson.M{"_id": "opts-x"}, bson.M{"name": "x"}, &BulkUpdateOptions{
+		Upsert: true,
+		Hint:   bson.M{"_id": 1},
+	})
+	bulk.RemoveOneWithOpts(bson.M{"name": "NAME2"}, &BulkRemoveOptions{
+		Collation: bson.M{"locale": "en", "strength": 2},
+	})
+	bulk.RemoveWithOpts(bson.M{"name": "Name3"}, nil)
+	reply, err := bulk.Execute()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if reply.Inserted != 5 || reply.Matched != 6 || reply.Modified != 6 ||
+		reply.Removed != 2 || reply.Upserted != 1 || reply.UpsertedIDs[7] != "opts-x" {
+		t.Error(reply)
+		return
+	}
+	one := bson.M{}
+	err = col.FindID("opts-1", nil, &one)
+	if err != nil || one["lower"] != "name1" {
+		t.Errorf("%v,%v", one, err)
+		return
+	}
+	//
+	//test error
+	bulk = col.NewBulk(true)
+	bulk.Add(&UpdateManyModel{Filter: bson.M{}})
+	_, err = bulk.Execute()
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	bulk = col.NewBulk(true)
+	bulk.UpdateWithOpts(bson.M{}, bson.M{"a": 1}, nil) //not update operator.
+	_, err = bulk.Execute()
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestBulkWrite(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	models := []WriteModel{
+		&InsertOneModel{Document: bson.M{"_id": "model-1", "a": 1}},
+		&InsertOneModel{Document: bson.M{"_id": "model-2", "a": 2}},
+		&InsertOneModel{Document: bson.M{"_id": "model-3", "a": 3}},
+		&UpdateOneModel{Filter: bson.M{"_id": "model-1"}, Update: bson.M{"$set": bson.M{"b": 1}}},
+		&UpdateManyModel{Filter: bson.M{"a": bson.M{"$gt": 1}}, Update: bson.M{"$set": bson.M{"b": 2}}},
+		&ReplaceOneModel{Filter: bson.M{"_id": "model-4"}, Replacement: bson.M{"a": 4}, Opts: &BulkUpdateOptions{Upsert: true}},
+		&DeleteOneModel{Filter: bson.M{"_id": "model-2"}},
+		&DeleteManyModel{Filter: bson.M{"a": 3}},
+	}
+	//serialize for queueing.
+	for i, model := range models {
+		bys, err := MarshalModel(model)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		models[i], err = UnmarshalModel(bys)
+		if err != nil || models[i].ModelName() != model.ModelName() {
+			t.Errorf("%v,%v", models[i], err)
+			return
+		}
+	}
+	reply, err := col.BulkWrite(models, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if reply.Inserted != 3 || reply.Matched != 3 || reply.Modified != 3 ||
+		reply.Removed != 2 || reply.Upserted != 1 || reply.UpsertedIDs[5] != "model-4" {
+		t.Error(reply)
+		return
+	}
+	//
+	reply, err = col.BulkWrite([]WriteModel{
+		&InsertOneModel{Document: bson.M{"_id": "model-1"}},
+		&InsertOneModel{Document: bson.M{"_id": "model-5"}},
+	}, &BulkWriteOptions{Unordered: true})
+	if _, ok := err.(*BulkWriteError); !ok || reply.Inserted != 1 {
+		t.Errorf("%v,%v", reply, err)
+		return
+	}
+}
+
 func runCreateFind(col *Collection, rid int64) {
 	err := col.Insert(map[string]interface{}{
 		"bench":   rid,
@@ -1346,3 +1724,29 @@ func BenchmarkBulkUpsert(b *testing.B) {
 		}
 	})
 }
+
+func TestCheckUpdate(t *testing.T) {
+	for _, update := range []interface{}{
+		bson.M{"$set": bson.M{"a": 1}},
+		bson.D{{Name: "$inc", Value: bson.M{"a": 1}}},
+		[]bson.M{{"$set": bson.M{"a": 1}}},
+		NewPipeline().Stage("$set", bson.M{"a": 1}),
+	} {
+		if err := checkUpdate(update); err != nil {
+			t.Errorf("%v error:%v", update, err)
+			return
+		}
+	}
+	for _, update := range []interface{}{
+		bson.M{"a": 1},
+		bson.D{{Name: "a", Value: 1}, {Name: "$set", Value: bson.M{"a": 1}}},
+		bson.M{},
+		nil,
+		TestCheckUpdate,
+	} {
+		if err := checkUpdate(update); err == nil {
+			t.Errorf("%v not error", update)
+			return
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package mongoc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//OplogEntry is the typed entry of local.oplog.rs.
+//for more https://docs.mongodb.com/manual/core/replica-set-oplog/
+type OplogEntry struct {
+	Timestamp bson.MongoTimestamp `bson:"ts"`
+	Op        string              `bson:"op"` //i:insert,u:update,d:delete,c:command,n:noop
+	Namespace string              `bson:"ns"`
+	Object    bson.M              `bson:"o"`
+	Object2   bson.M              `bson:"o2,omitempty"` //the query of update.
+}
+
+//CheckpointStore is the interface to load/save the last handled oplog timestamp by name.
+type CheckpointStore interface {
+	//Load the last timestamp by name, it should return zero when not found.
+	Load(name string) (ts bson.MongoTimestamp, err error)
+	//Save the last timestamp by name.
+	Save(name string, ts bson.MongoTimestamp) error
+}
+
+//MemoryCheckpointStore is the CheckpointStore impl by memory map, it is used for testing.
+type MemoryCheckpointStore struct {
+	checkpoints map[string]bson.MongoTimestamp
+	lck         sync.RWMutex
+}
+
+//NewMemoryCheckpointStore will create the memory checkpoint store.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints: map[string]bson.MongoTimestamp{},
+	}
+}
+
+//Load is the CheckpointStore impl.
+func (m *MemoryCheckpointStore) Load(name string) (ts bson.MongoTimestamp, err error) {
+	m.lck.RLock()
+	ts = m.checkpoints[name]
+	m.lck.RUnlock()
+	return
+}
+
+//Save is the CheckpointStore impl.
+func (m *MemoryCheckpointStore) Save(name string, ts bson.MongoTimestamp) (err error) {
+	m.lck.Lock()
+	m.checkpoints[name] = ts
+	m.lck.Unlock()
+	return
+}
+
+//CollectionCheckpointStore is the CheckpointStore impl by collection, the document is {_id:name,ts:timestamp}.
+type CollectionCheckpointStore struct {
+	C *Collection
+}
+
+//Load is the CheckpointStore impl.
+func (c *CollectionCheckpointStore) Load(name string) (ts bson.MongoTimestamp, err error) {
+	var checkpoint = struct {
+		Timestamp bson.MongoTimestamp `bson:"ts"`
+	}{}
+	err = c.C.FindOne(bson.M{"_id": name}, nil, &checkpoint)
+	if err == ErrNotFound {
+		err = nil
+	}
+	ts = checkpoint.Timestamp
+	return
+}
+
+//Save is the CheckpointStore impl.
+func (c *CollectionCheckpointStore) Save(name string, ts bson.MongoTimestamp) (err error) {
+	_, err = c.C.UpdateOne(bson.M{"_id": name}, bson.M{"$set": bson.M{"ts": ts}}, &UpdateOptions{Upsert: true})
+	return
+}
+
+//OplogReader is the consumer to tail local.oplog.rs by namespace/op filter,
+//it is used when change streams is not available.
+//
+//the handled timestamp is saved to store by CheckpointInterval and on stopping,
+//so the entry may be handled again after restarting, the handler should be idempotent.
+//
+//the writes in multi-document transaction is recorded as one applyOps entry on admin.$cmd,
+//it is unpacked to entries by namespace/op filter with the timestamp of applyOps entry.
+//the prepared transaction on sharded cluster which is committed by commitTransaction entry is not supported.
+type OplogReader struct {
+	Pool               Poolable
+	Name               string              //the checkpoint name.
+	Store              CheckpointStore     //the checkpoint store.
+	Namespaces         []string            //the namespace filter like db.collection, empty is all.
+	Ops                []string            //the op filter like i/u/d, empty is all.
+	StartAt            bson.MongoTimestamp //the start timestamp when checkpoint is not found, default is now.
+	MaxAwaitTime       time.Duration       //see TailOptions.MaxAwaitTime.
+	MaxRetry           int                 //see TailOptions.MaxRetry.
+	CheckpointInterval time.Duration       //the interval to save checkpoint, default is 1s.
+}
+
+//OplogReader will create the oplog reader by checkpoint name and store.
+func (p *Pool) OplogReader(name string, store CheckpointStore) *OplogReader {
+	if p.closed {
+		panic("pool is closed")
+	}
+	return &OplogReader{
+		Pool:               p,
+		Name:               name,
+		Store:              store,
+		MaxAwaitTime:       time.Second,
+		CheckpointInterval: time.Second,
+	}
+}
+
+//Query return the oplog query by namespace/op filter, the applyOps entry of transaction is matched by its operations.
+func (o *OplogReader) Query() bson.M {
+	var query = bson.M{}
+	var txn = bson.M{
+		"op":         "c",
+		"ns":         "admin.$cmd",
+		"o.applyOps": bson.M{"$exists": true},
+	}
+	if len(o.Namespaces) > 0 {
+		query["ns"] = bson.M{"$in": o.Namespaces}
+		txn["o.applyOps.ns"] = bson.M{"$in": o.Namespaces}
+	}
+	if len(o.Ops) > 0 {
+		query["op"] = bson.M{"$in": o.Ops}
+		txn["o.applyOps.op"] = bson.M{"$in": o.Ops}
+	}
+	if len(query) < 1 {
+		return query
+	}
+	return bson.M{"$or": []bson.M{query, txn}}
+}
+
+//unpack will unpack the applyOps entry to the entries matched by namespace/op filter,
+//the other entry is returned directly.
+func (o *OplogReader) unpack(entry *OplogEntry) (entries []*OplogEntry, err error) {
+	if entry.Op != "c" || entry.Namespace != "admin.$cmd" || entry.Object["applyOps"] == nil {
+		entries = []*OplogEntry{entry}
+		return
+	}
+	bys, err := bson.Marshal(entry.Object)
+	if err != nil {
+		return
+	}
+	var txn = struct {
+		ApplyOps []*OplogEntry `bson:"applyOps"`
+	}{}
+	err = bson.Unmarshal(bys, &txn)
+	if err != nil {
+		return
+	}
+	for _, one := range txn.ApplyOps {
+		if !o.match(one) {
+			continue
+		}
+		one.Timestamp = entry.Timestamp
+		entries = append(entries, one)
+	}
+	return
+}
+
+//match return true when the entry is matched by namespace/op filter.
+func (o *OplogReader) match(entry *OplogEntry) bool {
+	var contains = func(list []string, value string) bool {
+		for _, one := range list {
+			if one == value {
+				return true
+			}
+		}
+		return len(list) < 1
+	}
+	return contains(o.Namespaces, entry.Namespace) && contains(o.Ops, entry.Op)
+}
+
+//Run will tail the oplog and call handler for each entry until context is done or error.
+//the handler error will stop the reader and be returned, the failed entry is not checkpointed.
+func (o *OplogReader) Run(ctx context.Context, handler func(entry *OplogEntry) error) (err error) {
+	last, err := o.Store.Load(o.Name)
+	if err != nil {
+		return
+	}
+	if last < 1 {
+		last = o.StartAt
+	}
+	if last < 1 {
+		last = bson.MongoTimestamp(time.Now().Unix() << 32)
+	}
+	var oplog = &Collection{
+		Name:   "oplog.rs",
+		DbName: "local",
+		Pool:   o.Pool,
+	}
+	var tail = oplog.Tail(o.Query(), &TailOptions{
+		Context:      ctx,
+		ResumeField:  "ts",
+		ResumeAfter:  last,
+		MaxAwaitTime: o.MaxAwaitTime,
+		MaxRetry:     o.MaxRetry,
+		OplogReplay:  true,
+	})
+	defer tail.Close()
+	var saved, saveTime = last, time.Now()
+	for {
+		var entry = &OplogEntry{}
+		if !tail.Next(entry) {
+			err = tail.Err()
+			break
+		}
+		var entries []*OplogEntry
+		entries, err = o.unpack(entry)
+		if err != nil {
+			break
+		}
+		for _, one := range entries {
+			err = handler(one)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+		last = entry.Timestamp
+		if time.Since(saveTime) >= o.CheckpointInterval {
+			err = o.Store.Save(o.Name, last)
+			if err != nil {
+				return
+			}
+			saved, saveTime = last, time.Now()
+		}
+	}
+	if last != saved {
+		if serr := o.Store.Save(o.Name, last); err == nil {
+			err = serr
+		}
+	}
+	return
+}
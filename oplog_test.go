@@ -0,0 +1,118 @@
+package mongoc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestOplogReader(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	store := &CollectionCheckpointStore{C: pool.C("test", "mongoc_checkpoint")}
+	store.C.RemoveAll(nil)
+	reader := pool.OplogReader("indexer", store)
+	reader.Namespaces = []string{"test.mongoc"}
+	reader.Ops = []string{"i", "d"}
+	reader.StartAt = bson.MongoTimestamp(time.Now().Add(-time.Second).Unix() << 32)
+	reader.MaxAwaitTime = 100 * time.Millisecond
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			col.Insert(bson.M{"_id": fmt.Sprintf("oplog-%v", i)})
+			col.UpdateOne(bson.M{"_id": fmt.Sprintf("oplog-%v", i)}, bson.M{"$set": bson.M{"a": i}}, nil)
+		}
+		col.RemoveAll(nil)
+		pool.C("test", "mongoc_other").Insert(bson.M{"a": 1})
+	}()
+	var entries []*OplogEntry
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := reader.Run(ctx, func(entry *OplogEntry) error {
+		entries = append(entries, entry)
+		if len(entries) == 6 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil || len(entries) != 6 {
+		t.Errorf("entries:%v,err:%v", entries, err)
+		return
+	}
+	for i, entry := range entries {
+		if entry.Namespace != "test.mongoc" || (i < 3 && entry.Op != "i") || (i >= 3 && entry.Op != "d") {
+			t.Errorf("entry:%v", entry)
+			return
+		}
+	}
+	last, err := store.Load("indexer")
+	if err != nil || last != entries[5].Timestamp {
+		t.Errorf("last:%v,err:%v", last, err)
+		return
+	}
+	//
+	//resume from checkpoint and stop by handler error
+	col.Insert(bson.M{"_id": "oplog-resume"})
+	err = reader.Run(context.Background(), func(entry *OplogEntry) error {
+		if entry.Object["_id"] != "oplog-resume" {
+			return fmt.Errorf("entry:%v", entry)
+		}
+		return fmt.Errorf("stop")
+	})
+	if err == nil || err.Error() != "stop" {
+		t.Error(err)
+		return
+	}
+	last2, _ := store.Load("indexer")
+	if last2 != last {
+		t.Errorf("checkpoint is saved on handler error")
+		return
+	}
+	//
+	//memory store
+	mem := NewMemoryCheckpointStore()
+	mem.Save("a", 100)
+	if ts, _ := mem.Load("a"); ts != 100 {
+		t.Error("error")
+		return
+	}
+}
+
+func TestOplogUnpack(t *testing.T) {
+	reader := &OplogReader{Namespaces: []string{"test.mongoc"}, Ops: []string{"i", "u"}}
+	if query := reader.Query(); len(query["$or"].([]bson.M)) != 2 {
+		t.Errorf("query:%v", query)
+		return
+	}
+	if query := (&OplogReader{}).Query(); len(query) != 0 {
+		t.Errorf("query:%v", query)
+		return
+	}
+	entries, err := reader.unpack(&OplogEntry{
+		Timestamp: 100,
+		Op:        "c",
+		Namespace: "admin.$cmd",
+		Object: bson.M{
+			"applyOps": []interface{}{
+				bson.M{"op": "i", "ns": "test.mongoc", "o": bson.M{"_id": 1}},
+				bson.M{"op": "i", "ns": "test.other", "o": bson.M{"_id": 2}},
+				bson.M{"op": "d", "ns": "test.mongoc", "o": bson.M{"_id": 3}},
+				bson.M{"op": "u", "ns": "test.mongoc", "o": bson.M{"$set": bson.M{"a": 1}}, "o2": bson.M{"_id": 4}},
+			},
+		},
+	})
+	if err != nil || len(entries) != 2 || entries[0].Object["_id"] != 1 || entries[1].Object2["_id"] != 4 || entries[1].Timestamp != 100 {
+		t.Errorf("entries:%v,err:%v", entries, err)
+		return
+	}
+	entry := &OplogEntry{Op: "c", Namespace: "test.$cmd", Object: bson.M{"drop": "mongoc"}}
+	entries, err = reader.unpack(entry)
+	if err != nil || len(entries) != 1 || entries[0] != entry {
+		t.Errorf("entries:%v,err:%v", entries, err)
+		return
+	}
+}
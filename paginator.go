@@ -0,0 +1,248 @@
+package mongoc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ErrInvalidToken is the error when page token is not valid or signed by other secret.
+var ErrInvalidToken = fmt.Errorf("invalid page token")
+
+//Paginator is the keyset pagination helper, it query the page by the sort key values of last document
+//instead of skip, following:
+//
+//	paginator := col.Paginator([]byte("secret"), "-created", "_id")
+//	var items []*Item
+//	page, err := paginator.Page(bson.M{"status": 1}, token, 20, &items)
+//
+//the _id is appended to sort keys when it is not found for keeping the order unique,
+//and the sort key should not be missing or null in document.
+type Paginator struct {
+	C      *Collection
+	Keys   []string    //the sort keys by ParseSorted format.
+	Secret []byte      //the secret to sign page token.
+	Fields interface{} //the projection of document, the sort keys must be included.
+}
+
+//Page is the result of Paginator.Page.
+type Page struct {
+	Items     interface{} //the slice of document, it is same as the val.
+	NextToken string      //the token of next page, it is empty when it is last page.
+	PrevToken string      //the token of previous page, it is empty when it is first page.
+}
+
+type pageToken struct {
+	Forward bool          `bson:"f"`
+	Values  []interface{} `bson:"v"`
+}
+
+//Paginator will create the keyset paginator by secret and sort keys.
+//the special key like $text:xx is not supported, which can not be sorted by value, it is returned as error by Page.
+func (c *Collection) Paginator(secret []byte, keys ...string) *Paginator {
+	var hasID bool
+	for _, key := range keys {
+		if strings.TrimPrefix(key, "-") == "_id" {
+			hasID = true
+		}
+	}
+	if !hasID {
+		keys = append(keys, "_id")
+	}
+	return &Paginator{
+		C:      c,
+		Keys:   keys,
+		Secret: secret,
+	}
+}
+
+//checkPageKeys will check the sort keys is not special key.
+func checkPageKeys(keys []string) (err error) {
+	for _, key := range keys {
+		if strings.HasPrefix(key, "$") {
+			err = fmt.Errorf("the page sort key(%v) is not supported", key)
+			return
+		}
+	}
+	return
+}
+
+//Page will query one page by query and token, the first page is queried by empty token.
+//the val must be pointer to slice, and the limit must be greater than 0.
+func (p *Paginator) Page(query interface{}, token string, limit int, val interface{}) (page *Page, err error) {
+	if limit < 1 {
+		err = fmt.Errorf("the page limit must be greater than 0, but %v", limit)
+		return
+	}
+	err = checkPageKeys(p.Keys)
+	if err != nil {
+		return
+	}
+	targetVal := reflect.Indirect(reflect.ValueOf(val))
+	if targetVal.Kind() != reflect.Slice {
+		err = fmt.Errorf("the val must be pointer to slice, but %v", reflect.TypeOf(val))
+		return
+	}
+	var current = &pageToken{Forward: true}
+	if len(token) > 0 {
+		current, err = p.decodeToken(token)
+		if err != nil {
+			return
+		}
+	}
+	if query == nil {
+		query = bson.M{}
+	}
+	var pipeline = NewPipeline()
+	if current.Values != nil {
+		pipeline.Match(bson.M{
+			"$and": []interface{}{query, p.rangeQuery(current.Values, current.Forward)},
+		})
+	} else {
+		pipeline.Match(query)
+	}
+	pipeline.Sort(p.sortKeys(current.Forward)...).Limit(limit + 1)
+	if p.Fields != nil {
+		pipeline.Project(p.Fields)
+	}
+	var raws []bson.Raw
+	err = p.C.Aggregate(pipeline, nil, &raws)
+	if err != nil {
+		return
+	}
+	var more = len(raws) > limit
+	if more {
+		raws = raws[:limit]
+	}
+	if !current.Forward { //reverse to the sort order.
+		for i, j := 0, len(raws)-1; i < j; i, j = i+1, j-1 {
+			raws[i], raws[j] = raws[j], raws[i]
+		}
+	}
+	newVal := reflect.MakeSlice(targetVal.Type(), 0, len(raws))
+	for _, raw := range raws {
+		elemVal := reflect.New(targetVal.Type().Elem())
+		err = raw.Unmarshal(elemVal.Interface())
+		if err != nil {
+			return
+		}
+		newVal = reflect.Append(newVal, reflect.Indirect(elemVal))
+	}
+	targetVal.Set(newVal)
+	page = &Page{Items: targetVal.Interface()}
+	if len(raws) < 1 {
+		return
+	}
+	if (current.Forward && more) || (!current.Forward && current.Values != nil) {
+		page.NextToken, err = p.encodeToken(true, raws[len(raws)-1])
+		if err != nil {
+			return
+		}
+	}
+	if (!current.Forward && more) || (current.Forward && current.Values != nil) {
+		page.PrevToken, err = p.encodeToken(false, raws[0])
+	}
+	return
+}
+
+//sortKeys return the sort keys, the direction is reversed when it is not forward.
+func (p *Paginator) sortKeys(forward bool) (keys []string) {
+	if forward {
+		return p.Keys
+	}
+	for _, key := range p.Keys {
+		if strings.HasPrefix(key, "-") {
+			keys = append(keys, strings.TrimPrefix(key, "-"))
+		} else {
+			keys = append(keys, "-"+key)
+		}
+	}
+	return
+}
+
+//rangeQuery will build the query of document after the sort key values, following:
+//
+//	{$or:[{k1:{$gt:v1}},{k1:v1,k2:{$gt:v2}}]}
+func (p *Paginator) rangeQuery(values []interface{}, forward bool) bson.M {
+	var or = []interface{}{}
+	for i, sorted := range ParseSorted(p.sortKeys(forward)...) {
+		if i >= len(values) {
+			break
+		}
+		var cond = bson.D{}
+		for j := 0; j < i; j++ {
+			cond = append(cond, bson.DocElem{Name: strings.TrimPrefix(p.Keys[j], "-"), Value: values[j]})
+		}
+		var op = "$gt"
+		if sorted.Value == -1 {
+			op = "$lt"
+		}
+		cond = append(cond, bson.DocElem{Name: sorted.Name, Value: bson.M{op: values[i]}})
+		or = append(or, cond)
+	}
+	return bson.M{"$or": or}
+}
+
+//encodeToken will encode the sort key values of document to signed token.
+func (p *Paginator) encodeToken(forward bool, raw bson.Raw) (token string, err error) {
+	var doc = bson.M{}
+	err = raw.Unmarshal(&doc)
+	if err != nil {
+		return
+	}
+	var values = []interface{}{}
+	for _, key := range p.Keys {
+		values = append(values, lookupField(doc, strings.TrimPrefix(key, "-")))
+	}
+	bys, err := bson.Marshal(&pageToken{Forward: forward, Values: values})
+	if err != nil {
+		return
+	}
+	bys = append(bys, p.sign(bys)...)
+	token = base64.RawURLEncoding.EncodeToString(bys)
+	return
+}
+
+//decodeToken will verify and decode the token.
+func (p *Paginator) decodeToken(token string) (current *pageToken, err error) {
+	bys, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(bys) <= sha256.Size {
+		err = ErrInvalidToken
+		return
+	}
+	data, sign := bys[:len(bys)-sha256.Size], bys[len(bys)-sha256.Size:]
+	if !hmac.Equal(sign, p.sign(data)) {
+		err = ErrInvalidToken
+		return
+	}
+	current = &pageToken{}
+	err = bson.Unmarshal(data, current)
+	if err != nil || len(current.Values) != len(p.Keys) {
+		err = ErrInvalidToken
+	}
+	return
+}
+
+func (p *Paginator) sign(data []byte) []byte {
+	var mac = hmac.New(sha256.New, p.Secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+//lookupField will get the value by dotted field name, it return nil when not found.
+func lookupField(doc bson.M, name string) (value interface{}) {
+	value = doc
+	for _, part := range strings.Split(name, ".") {
+		sub, ok := value.(bson.M)
+		if !ok {
+			return nil
+		}
+		value = sub[part]
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package mongoc
+
+import (
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestPaginator(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	for i := 0; i < 25; i++ {
+		col.Insert(bson.M{"_id": fmt.Sprintf("page-%02d", i), "a": i % 5, "b": 1})
+	}
+	paginator := col.Paginator([]byte("secret"), "-a")
+	var all []string
+	var tokens []string
+	var token string
+	for {
+		var items []bson.M
+		page, err := paginator.Page(bson.M{"b": 1}, token, 10, &items)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, item := range items {
+			all = append(all, item["_id"].(string))
+		}
+		if len(page.Items.([]bson.M)) != len(items) {
+			t.Error("error")
+			return
+		}
+		if len(page.NextToken) < 1 {
+			break
+		}
+		tokens = append(tokens, page.PrevToken)
+		token = page.NextToken
+	}
+	if len(all) != 25 || all[0] != "page-04" || all[1] != "page-09" || all[24] != "page-20" {
+		t.Errorf("all:%v", all)
+		return
+	}
+	if tokens[0] != "" {
+		t.Errorf("tokens:%v", tokens)
+		return
+	}
+	//
+	//previous
+	var last []bson.M
+	page, err := paginator.Page(bson.M{"b": 1}, token, 10, &last)
+	if err != nil || len(last) != 5 {
+		t.Errorf("last:%v,err:%v", last, err)
+		return
+	}
+	var prev []bson.M
+	page, err = paginator.Page(bson.M{"b": 1}, page.PrevToken, 10, &prev)
+	if err != nil || len(prev) != 10 || prev[0]["_id"] != all[10] || prev[9]["_id"] != all[19] {
+		t.Errorf("prev:%v,err:%v", prev, err)
+		return
+	}
+	if len(page.NextToken) < 1 || len(page.PrevToken) < 1 {
+		t.Errorf("page:%v", page)
+		return
+	}
+	//
+	//error
+	_, err = paginator.Page(nil, "", 10, prev)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = paginator.Page(TestPaginator, "", 10, &prev)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestPageToken(t *testing.T) {
+	paginator := (&Collection{}).Paginator([]byte("secret"), "-a", "b.c")
+	if fmt.Sprintf("%v", paginator.Keys) != "[-a b.c _id]" {
+		t.Error(paginator.Keys)
+		return
+	}
+	raw := bson.Raw{}
+	bys, _ := bson.Marshal(bson.M{"_id": 1, "a": "x", "b": bson.M{"c": 2}})
+	bson.Unmarshal(bys, &raw)
+	token, err := paginator.encodeToken(true, raw)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	current, err := paginator.decodeToken(token)
+	if err != nil || !current.Forward || fmt.Sprintf("%v", current.Values) != "[x 2 1]" {
+		t.Errorf("current:%v,err:%v", current, err)
+		return
+	}
+	//
+	//invalid token
+	other := (&Collection{}).Paginator([]byte("other"), "-a", "b.c")
+	if _, err = other.decodeToken(token); err != ErrInvalidToken {
+		t.Error(err)
+		return
+	}
+	if _, err = paginator.decodeToken("xx"); err != ErrInvalidToken {
+		t.Error(err)
+		return
+	}
+	if _, err = paginator.decodeToken(token[:len(token)-2] + "AA"); err != ErrInvalidToken {
+		t.Error(err)
+		return
+	}
+	//
+	//range query
+	query := paginator.rangeQuery(current.Values, true)
+	if fmt.Sprintf("%v", query) != "map[$or:[[{a map[$lt:x]}] [{a x} {b.c map[$gt:2]}] [{a x} {b.c 2} {_id map[$gt:1]}]]]" {
+		t.Error(query)
+		return
+	}
+	query = paginator.rangeQuery(current.Values, false)
+	if fmt.Sprintf("%v", query) != "map[$or:[[{a map[$gt:x]}] [{a x} {b.c map[$lt:2]}] [{a x} {b.c 2} {_id map[$lt:1]}]]]" {
+		t.Error(query)
+		return
+	}
+	//
+	//invalid keys and limit
+	var items []bson.M
+	if _, err = paginator.Page(nil, "", 0, &items); err == nil {
+		t.Error("not error")
+		return
+	}
+	special := (&Collection{}).Paginator([]byte("secret"), "$text:a")
+	if _, err = special.Page(nil, "", 10, &items); err == nil {
+		t.Error("not error")
+		return
+	}
+}
@@ -0,0 +1,249 @@
+package mongoc
+
+import bson "gopkg.in/bson.v2"
+
+//Pipeline is the fluent builder for aggregation pipeline, following:
+//
+//	NewPipeline().Match(bson.M{"a": 1}).Sort("-b").Limit(10)
+//
+//it can be used anywhere the pipeline is accepted, like Pipe/Aggregate.
+//for more https://docs.mongodb.com/manual/reference/operator/aggregation-pipeline/
+type Pipeline struct {
+	Stages []bson.D
+}
+
+//NewPipeline will create one empty pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		Stages: []bson.D{},
+	}
+}
+
+//GetBSON is the bson.Getter impl, it will marshal the pipeline to stage array.
+func (p *Pipeline) GetBSON() (interface{}, error) {
+	return p.Stages, nil
+}
+
+//Stage will append one stage by operator name and value, it is used for stages not wrapped.
+func (p *Pipeline) Stage(name string, value interface{}) *Pipeline {
+	p.Stages = append(p.Stages, bson.D{
+		{
+			Name:  name,
+			Value: value,
+		},
+	})
+	return p
+}
+
+//Match will append $match stage by filter.
+func (p *Pipeline) Match(filter interface{}) *Pipeline {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	return p.Stage("$match", filter)
+}
+
+//Project will append $project stage by fields.
+func (p *Pipeline) Project(fields interface{}) *Pipeline {
+	return p.Stage("$project", fields)
+}
+
+//Group will append $group stage by _id expression and accumulator fields.
+func (p *Pipeline) Group(id interface{}, fields bson.M) *Pipeline {
+	var group = bson.D{
+		{
+			Name:  "_id",
+			Value: id,
+		},
+	}
+	for name, value := range fields {
+		group = append(group, bson.DocElem{Name: name, Value: value})
+	}
+	return p.Stage("$group", group)
+}
+
+//Sort will append $sort stage by sorted keys, the keys is parsed by ParseSorted.
+func (p *Pipeline) Sort(keys ...string) *Pipeline {
+	return p.Stage("$sort", ParseSorted(keys...))
+}
+
+//Skip will append $skip stage.
+func (p *Pipeline) Skip(n int) *Pipeline {
+	return p.Stage("$skip", n)
+}
+
+//Limit will append $limit stage.
+func (p *Pipeline) Limit(n int) *Pipeline {
+	return p.Stage("$limit", n)
+}
+
+//Lookup will append $lookup stage to join the document from other collection.
+func (p *Pipeline) Lookup(from, localField, foreignField, as string) *Pipeline {
+	return p.Stage("$lookup", bson.D{
+		{
+			Name:  "from",
+			Value: from,
+		},
+		{
+			Name:  "localField",
+			Value: localField,
+		},
+		{
+			Name:  "foreignField",
+			Value: foreignField,
+		},
+		{
+			Name:  "as",
+			Value: as,
+		},
+	})
+}
+
+//Unwind will append $unwind stage by array field path, the path is not needed to start with $.
+func (p *Pipeline) Unwind(path string, preserveNullAndEmptyArrays bool) *Pipeline {
+	if len(path) > 0 && path[0] != '$' {
+		path = "$" + path
+	}
+	if !preserveNullAndEmptyArrays {
+		return p.Stage("$unwind", path)
+	}
+	return p.Stage("$unwind", bson.D{
+		{
+			Name:  "path",
+			Value: path,
+		},
+		{
+			Name:  "preserveNullAndEmptyArrays",
+			Value: true,
+		},
+	})
+}
+
+//Facet will append $facet stage by sub pipeline.
+func (p *Pipeline) Facet(facets map[string]*Pipeline) *Pipeline {
+	var facet = bson.M{}
+	for name, sub := range facets {
+		facet[name] = sub.Stages
+	}
+	return p.Stage("$facet", facet)
+}
+
+//Out will append $out stage to write result to collection.
+func (p *Pipeline) Out(collection string) *Pipeline {
+	return p.Stage("$out", collection)
+}
+
+//Merge will append $merge stage to write result to collection,
+//the on/whenMatched/whenNotMatched will be omitted when it is empty.
+//for more https://docs.mongodb.com/manual/reference/operator/aggregation/merge/
+func (p *Pipeline) Merge(into interface{}, on []string, whenMatched interface{}, whenNotMatched string) *Pipeline {
+	var merge = bson.D{
+		{
+			Name:  "into",
+			Value: into,
+		},
+	}
+	if len(on) > 0 {
+		merge = append(merge, bson.DocElem{Name: "on", Value: on})
+	}
+	if whenMatched != nil && whenMatched != "" {
+		if sub, ok := whenMatched.(*Pipeline); ok {
+			whenMatched = sub.Stages
+		}
+		merge = append(merge, bson.DocElem{Name: "whenMatched", Value: whenMatched})
+	}
+	if len(whenNotMatched) > 0 {
+		merge = append(merge, bson.DocElem{Name: "whenNotMatched", Value: whenNotMatched})
+	}
+	return p.Stage("$merge", merge)
+}
+
+//CurrentOp will append $currentOp stage, it must be run on admin database by Pool.Aggregate.
+func (p *Pipeline) CurrentOp(opts bson.M) *Pipeline {
+	if opts == nil {
+		opts = bson.M{}
+	}
+	return p.Stage("$currentOp", opts)
+}
+
+//ListLocalSessions will append $listLocalSessions stage, it must be run by Pool.Aggregate.
+func (p *Pipeline) ListLocalSessions(opts bson.M) *Pipeline {
+	if opts == nil {
+		opts = bson.M{}
+	}
+	return p.Stage("$listLocalSessions", opts)
+}
+
+//SetWindowFields will append $setWindowFields stage, the partitionBy can be nil and the sortBy is parsed by ParseSorted.
+//for more https://docs.mongodb.com/manual/reference/operator/aggregation/setWindowFields/
+func (p *Pipeline) SetWindowFields(partitionBy interface{}, sortBy []string, output bson.M) *Pipeline {
+	var window = bson.D{}
+	if partitionBy != nil {
+		window = append(window, bson.DocElem{Name: "partitionBy", Value: partitionBy})
+	}
+	if len(sortBy) > 0 {
+		window = append(window, bson.DocElem{Name: "sortBy", Value: ParseSorted(sortBy...)})
+	}
+	window = append(window, bson.DocElem{Name: "output", Value: output})
+	return p.Stage("$setWindowFields", window)
+}
+
+//Densify will append $densify stage to fill the gap of field by step, the unit is needed when field is date,
+//the bounds is full/partition or [lower, upper].
+//for more https://docs.mongodb.com/manual/reference/operator/aggregation/densify/
+func (p *Pipeline) Densify(field string, partitionByFields []string, step interface{}, unit string, bounds interface{}) *Pipeline {
+	var densify = bson.D{
+		{
+			Name:  "field",
+			Value: field,
+		},
+	}
+	if len(partitionByFields) > 0 {
+		densify = append(densify, bson.DocElem{Name: "partitionByFields", Value: partitionByFields})
+	}
+	var rng = bson.D{
+		{
+			Name:  "step",
+			Value: step,
+		},
+	}
+	if len(unit) > 0 {
+		rng = append(rng, bson.DocElem{Name: "unit", Value: unit})
+	}
+	rng = append(rng, bson.DocElem{Name: "bounds", Value: bounds})
+	densify = append(densify, bson.DocElem{Name: "range", Value: rng})
+	return p.Stage("$densify", densify)
+}
+
+//Downsample will append $group stage to group the time-series document by metaField and timeField truncated by unit/binSize,
+//the _id of result is {time:truncated time, meta:metaField}, and the fields is accumulator like Group.
+func (p *Pipeline) Downsample(options *TimeSeries, unit string, binSize int, fields bson.M) *Pipeline {
+	var id = bson.D{
+		{
+			Name: "time",
+			Value: bson.D{
+				{
+					Name: "$dateTrunc",
+					Value: bson.D{
+						{
+							Name:  "date",
+							Value: "$" + options.TimeField,
+						},
+						{
+							Name:  "unit",
+							Value: unit,
+						},
+						{
+							Name:  "binSize",
+							Value: binSize,
+						},
+					},
+				},
+			},
+		},
+	}
+	if len(options.MetaField) > 0 {
+		id = append(id, bson.DocElem{Name: "meta", Value: "$" + options.MetaField})
+	}
+	return p.Group(id, fields)
+}
@@ -0,0 +1,105 @@
+package mongoc
+
+import (
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestPipeline(t *testing.T) {
+	pipeline := NewPipeline().
+		Match(nil).
+		Project(bson.M{"a": 1}).
+		Group("$a", bson.M{"n": bson.M{"$sum": 1}}).
+		Sort("-n", "_id").
+		Skip(1).
+		Limit(2).
+		Lookup("other", "_id", "a", "others").
+		Unwind("others", false).
+		Unwind("$others", true).
+		Facet(map[string]*Pipeline{
+			"count": NewPipeline().Stage("$count", "n"),
+		}).
+		Merge("target", []string{"_id"}, NewPipeline().Project(bson.M{"a": 1}), "insert")
+	if len(pipeline.Stages) != 11 {
+		t.Errorf("stages error:%v", pipeline.Stages)
+		return
+	}
+	names := []string{"$match", "$project", "$group", "$sort", "$skip", "$limit", "$lookup", "$unwind", "$unwind", "$facet", "$merge"}
+	for i, name := range names {
+		if pipeline.Stages[i][0].Name != name {
+			t.Errorf("stage %v error:%v", i, pipeline.Stages[i])
+			return
+		}
+	}
+	if group := pipeline.Stages[2][0].Value.(bson.D); group[0].Name != "_id" || group[0].Value != "$a" {
+		t.Errorf("group error:%v", group)
+		return
+	}
+	if sorted := pipeline.Stages[3][0].Value.(bson.D); len(sorted) != 2 || sorted[0].Value != -1 {
+		t.Errorf("sort error:%v", sorted)
+		return
+	}
+	if pipeline.Stages[7][0].Value != "$others" {
+		t.Errorf("unwind error:%v", pipeline.Stages[7])
+		return
+	}
+	if merge := pipeline.Stages[10][0].Value.(bson.D); len(merge) != 4 {
+		t.Errorf("merge error:%v", merge)
+		return
+	}
+	if merge := NewPipeline().Out("a").Merge("b", nil, nil, "").Stages[1][0].Value.(bson.D); len(merge) != 1 {
+		t.Errorf("merge error:%v", merge)
+		return
+	}
+	//
+	bys, err := bson.Marshal(pipeline)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var raw bson.M
+	err = bson.Unmarshal(bys, &raw)
+	if err != nil || len(raw) != 11 {
+		t.Errorf("marshal error:%v,%v", raw, err)
+		return
+	}
+}
+
+func TestPipelineWindow(t *testing.T) {
+	options := &TimeSeries{TimeField: "ts", MetaField: "m"}
+	pipeline := NewPipeline().
+		SetWindowFields("$m", []string{"ts"}, bson.M{"avg": bson.M{"$avg": "$v"}}).
+		SetWindowFields(nil, nil, bson.M{"n": bson.M{"$documentNumber": bson.M{}}}).
+		Densify("ts", []string{"m"}, 1, "hour", "full").
+		Densify("v", nil, 10, "", []int{0, 100}).
+		Downsample(options, "minute", 5, bson.M{"v": bson.M{"$avg": "$v"}})
+	if len(pipeline.Stages) != 5 {
+		t.Errorf("stages error:%v", pipeline.Stages)
+		return
+	}
+	if window := pipeline.Stages[0][0].Value.(bson.D); len(window) != 3 || window[1].Value.(bson.D)[0].Name != "ts" {
+		t.Errorf("window error:%v", window)
+		return
+	}
+	if window := pipeline.Stages[1][0].Value.(bson.D); len(window) != 1 || window[0].Name != "output" {
+		t.Errorf("window error:%v", window)
+		return
+	}
+	if densify := pipeline.Stages[2][0].Value.(bson.D); len(densify) != 3 || len(densify[2].Value.(bson.D)) != 3 {
+		t.Errorf("densify error:%v", densify)
+		return
+	}
+	if densify := pipeline.Stages[3][0].Value.(bson.D); len(densify) != 2 || len(densify[1].Value.(bson.D)) != 2 {
+		t.Errorf("densify error:%v", densify)
+		return
+	}
+	if group := pipeline.Stages[4][0].Value.(bson.D); pipeline.Stages[4][0].Name != "$group" || len(group[0].Value.(bson.D)) != 2 {
+		t.Errorf("downsample error:%v", group)
+		return
+	}
+	if group := NewPipeline().Downsample(&TimeSeries{TimeField: "ts"}, "hour", 1, nil).Stages[0][0].Value.(bson.D); len(group[0].Value.(bson.D)) != 1 {
+		t.Errorf("downsample error:%v", group)
+		return
+	}
+}
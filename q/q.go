@@ -0,0 +1,329 @@
+//Package q is the fluent query builder, following:
+//
+//	col.Find(q.Eq("a", 1).Gt("b", 2).In("c", []int{1, 2}), nil, 0, 0, &docs)
+//
+//the query is marshaled to bson.D by GetBSON, so it can be used anywhere the selector is accepted,
+//and the invalid usage is returned as error on marshaling.
+package q
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	bson "gopkg.in/bson.v2"
+	mongoc "gopkg.in/mongoc.v1"
+)
+
+//Query is the query builder.
+type Query struct {
+	doc    bson.D
+	fields map[string]int //field name to doc index.
+	eqs    map[string]bool
+	typ    reflect.Type
+	err    error
+}
+
+//New will create the empty query.
+func New() *Query {
+	return &Query{
+		doc:    bson.D{},
+		fields: map[string]int{},
+		eqs:    map[string]bool{},
+	}
+}
+
+//For will create the empty query on struct type of v, the field name is go field path like Address.City,
+//and it will be mapped to bson name by bson tag.
+func For(v interface{}) *Query {
+	query := New()
+	query.typ = reflect.TypeOf(v)
+	return query
+}
+
+//Err return the first error of building.
+func (q *Query) Err() error {
+	return q.err
+}
+
+//D return the query document, it will panic when building fail.
+func (q *Query) D() bson.D {
+	if q.err != nil {
+		panic(q.err)
+	}
+	return q.doc
+}
+
+//GetBSON is the bson.Getter impl.
+func (q *Query) GetBSON() (interface{}, error) {
+	return q.doc, q.err
+}
+
+func (q *Query) fail(format string, args ...interface{}) *Query {
+	if q.err == nil {
+		q.err = fmt.Errorf(format, args...)
+	}
+	return q
+}
+
+func (q *Query) field(field string) (name string, ok bool) {
+	if len(field) < 1 || strings.TrimSpace(field) != field || strings.HasPrefix(field, "$") {
+		q.fail("invalid field name(%v)", field)
+		return
+	}
+	if q.typ == nil {
+		return field, true
+	}
+	name, err := mongoc.FieldPath(q.typ, field)
+	if err != nil {
+		q.fail("%v", err)
+		return
+	}
+	return name, true
+}
+
+//Eq append {field:value}.
+func (q *Query) Eq(field string, value interface{}) *Query {
+	name, ok := q.field(field)
+	if !ok {
+		return q
+	}
+	if _, having := q.fields[name]; having {
+		return q.fail("field(%v) is already used", name)
+	}
+	q.fields[name] = len(q.doc)
+	q.eqs[name] = true
+	q.doc = append(q.doc, bson.DocElem{Name: name, Value: value})
+	return q
+}
+
+//Op append {field:{op:value}}, the conditions on same field is merged.
+func (q *Query) Op(field, op string, value interface{}) *Query {
+	name, ok := q.field(field)
+	if !ok {
+		return q
+	}
+	if !fieldOps[op] {
+		return q.fail("invalid operator(%v) on field(%v)", op, name)
+	}
+	if q.eqs[name] {
+		return q.fail("field(%v) is already used by equal", name)
+	}
+	index, having := q.fields[name]
+	if !having {
+		index = len(q.doc)
+		q.fields[name] = index
+		q.doc = append(q.doc, bson.DocElem{Name: name, Value: bson.D{}})
+	}
+	ops := q.doc[index].Value.(bson.D)
+	for _, having := range ops {
+		if having.Name == op {
+			return q.fail("operator(%v) is already used on field(%v)", op, name)
+		}
+	}
+	q.doc[index].Value = append(ops, bson.DocElem{Name: op, Value: value})
+	return q
+}
+
+var fieldOps = map[string]bool{
+	"$eq": true, "$ne": true, "$gt": true, "$gte": true, "$lt": true, "$lte": true,
+	"$in": true, "$nin": true, "$exists": true, "$type": true, "$regex": true, "$options": true,
+	"$elemMatch": true, "$size": true, "$all": true, "$not": true, "$mod": true,
+	"$near": true, "$nearSphere": true, "$geoWithin": true, "$geoIntersects": true,
+	"$minDistance": true, "$maxDistance": true,
+}
+
+//Ne append {field:{$ne:value}}.
+func (q *Query) Ne(field string, value interface{}) *Query {
+	return q.Op(field, "$ne", value)
+}
+
+//Gt append {field:{$gt:value}}.
+func (q *Query) Gt(field string, value interface{}) *Query {
+	return q.Op(field, "$gt", value)
+}
+
+//Gte append {field:{$gte:value}}.
+func (q *Query) Gte(field string, value interface{}) *Query {
+	return q.Op(field, "$gte", value)
+}
+
+//Lt append {field:{$lt:value}}.
+func (q *Query) Lt(field string, value interface{}) *Query {
+	return q.Op(field, "$lt", value)
+}
+
+//Lte append {field:{$lte:value}}.
+func (q *Query) Lte(field string, value interface{}) *Query {
+	return q.Op(field, "$lte", value)
+}
+
+//In append {field:{$in:values}}, the values must be slice or array.
+func (q *Query) In(field string, values interface{}) *Query {
+	if !isList(values) {
+		return q.fail("the $in value of field(%v) must be slice, but %v", field, reflect.TypeOf(values))
+	}
+	return q.Op(field, "$in", values)
+}
+
+//Nin append {field:{$nin:values}}, the values must be slice or array.
+func (q *Query) Nin(field string, values interface{}) *Query {
+	if !isList(values) {
+		return q.fail("the $nin value of field(%v) must be slice, but %v", field, reflect.TypeOf(values))
+	}
+	return q.Op(field, "$nin", values)
+}
+
+//All append {field:{$all:values}}, the values must be slice or array.
+func (q *Query) All(field string, values interface{}) *Query {
+	if !isList(values) {
+		return q.fail("the $all value of field(%v) must be slice, but %v", field, reflect.TypeOf(values))
+	}
+	return q.Op(field, "$all", values)
+}
+
+//Exists append {field:{$exists:exists}}.
+func (q *Query) Exists(field string, exists bool) *Query {
+	return q.Op(field, "$exists", exists)
+}
+
+//Size append {field:{$size:size}}.
+func (q *Query) Size(field string, size int) *Query {
+	return q.Op(field, "$size", size)
+}
+
+//Regex append {field:{$regex:pattern,$options:options}}, the options is omitted when empty.
+func (q *Query) Regex(field, pattern, options string) *Query {
+	q.Op(field, "$regex", pattern)
+	if len(options) > 0 {
+		q.Op(field, "$options", options)
+	}
+	return q
+}
+
+//ElemMatch append {field:{$elemMatch:query}}.
+func (q *Query) ElemMatch(field string, query *Query) *Query {
+	if query.err != nil {
+		return q.fail("%v", query.err)
+	}
+	return q.Op(field, "$elemMatch", query.doc)
+}
+
+//Near append {field:{$near:{$geometry:point}}}, see mongoc.Near.
+func (q *Query) Near(field string, point *mongoc.Point, minDistance, maxDistance float64) *Query {
+	return q.geo(field, mongoc.Near(point, minDistance, maxDistance))
+}
+
+//GeoWithin append {field:{$geoWithin:{$geometry:geometry}}}, see mongoc.GeoWithin.
+func (q *Query) GeoWithin(field string, geometry interface{}) *Query {
+	return q.geo(field, mongoc.GeoWithin(geometry))
+}
+
+//GeoIntersects append {field:{$geoIntersects:{$geometry:geometry}}}, see mongoc.GeoIntersects.
+func (q *Query) GeoIntersects(field string, geometry interface{}) *Query {
+	return q.geo(field, mongoc.GeoIntersects(geometry))
+}
+
+func (q *Query) geo(field string, ops bson.D) *Query {
+	for _, op := range ops {
+		q.Op(field, op.Name, op.Value)
+	}
+	return q
+}
+
+//Or append {$or:[queries]}.
+func (q *Query) Or(queries ...*Query) *Query {
+	return q.logical("$or", queries)
+}
+
+//And append {$and:[queries]}.
+func (q *Query) And(queries ...*Query) *Query {
+	return q.logical("$and", queries)
+}
+
+//Nor append {$nor:[queries]}.
+func (q *Query) Nor(queries ...*Query) *Query {
+	return q.logical("$nor", queries)
+}
+
+func (q *Query) logical(op string, queries []*Query) *Query {
+	if len(queries) < 1 {
+		return q.fail("the %v must have at least one query", op)
+	}
+	if _, having := q.fields[op]; having {
+		return q.fail("operator(%v) is already used", op)
+	}
+	var docs = []bson.D{}
+	for _, query := range queries {
+		if query.err != nil {
+			return q.fail("%v", query.err)
+		}
+		docs = append(docs, query.doc)
+	}
+	q.fields[op] = len(q.doc)
+	q.doc = append(q.doc, bson.DocElem{Name: op, Value: docs})
+	return q
+}
+
+func isList(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	kind := reflect.TypeOf(v).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+//Eq create query with {field:value}.
+func Eq(field string, value interface{}) *Query {
+	return New().Eq(field, value)
+}
+
+//Ne create query with {field:{$ne:value}}.
+func Ne(field string, value interface{}) *Query {
+	return New().Ne(field, value)
+}
+
+//Gt create query with {field:{$gt:value}}.
+func Gt(field string, value interface{}) *Query {
+	return New().Gt(field, value)
+}
+
+//Gte create query with {field:{$gte:value}}.
+func Gte(field string, value interface{}) *Query {
+	return New().Gte(field, value)
+}
+
+//Lt create query with {field:{$lt:value}}.
+func Lt(field string, value interface{}) *Query {
+	return New().Lt(field, value)
+}
+
+//Lte create query with {field:{$lte:value}}.
+func Lte(field string, value interface{}) *Query {
+	return New().Lte(field, value)
+}
+
+//In create query with {field:{$in:values}}.
+func In(field string, values interface{}) *Query {
+	return New().In(field, values)
+}
+
+//Nin create query with {field:{$nin:values}}.
+func Nin(field string, values interface{}) *Query {
+	return New().Nin(field, values)
+}
+
+//Exists create query with {field:{$exists:exists}}.
+func Exists(field string, exists bool) *Query {
+	return New().Exists(field, exists)
+}
+
+//Or create query with {$or:[queries]}.
+func Or(queries ...*Query) *Query {
+	return New().Or(queries...)
+}
+
+//And create query with {$and:[queries]}.
+func And(queries ...*Query) *Query {
+	return New().And(queries...)
+}
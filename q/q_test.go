@@ -0,0 +1,141 @@
+package q
+
+import (
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+	mongoc "gopkg.in/mongoc.v1"
+	"gopkg.in/mongoc.v1/u"
+)
+
+type address struct {
+	City string `bson:"city"`
+}
+
+type user struct {
+	ID      string     `bson:"_id"`
+	Name    string     `bson:"n"`
+	Age     int        `bson:"age"`
+	Address *address   `bson:"addr"`
+	Tags    []*address `bson:"tags"`
+}
+
+func TestQuery(t *testing.T) {
+	query := Eq("a", 1).Gt("b", 2).Lt("b", 10).In("c", []int{1, 2}).Or(Eq("d", 1), Exists("e", false))
+	bys, err := bson.Marshal(query)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var doc bson.D
+	bson.Unmarshal(bys, &doc)
+	if fmt.Sprintf("%v", doc) != "[{a 1} {b [{$gt 2} {$lt 10}]} {c [{$in [1 2]}]} {$or [[{d 1}] [{e [{$exists false}]}]]}]" {
+		t.Error(doc)
+		return
+	}
+	if fmt.Sprintf("%v", New().Regex("a", "^x", "i").ElemMatch("b", Gte("c", 1)).D()) != "[{a [{$regex ^x} {$options i}]} {b [{$elemMatch [{c [{$gte 1}]}]}]}]" {
+		t.Error("regex error")
+		return
+	}
+	//
+	//geo
+	query = New().Near("loc", mongoc.NewPoint(1, 2), 0, 100).GeoWithin("area", mongoc.NewPolygon([][]float64{{0, 0}, {0, 1}, {1, 1}})).GeoIntersects("line", mongoc.NewLineString([]float64{0, 0}, []float64{1, 1}))
+	bys, err = bson.Marshal(query)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	doc = nil
+	bson.Unmarshal(bys, &doc)
+	if fmt.Sprintf("%v", doc) != "[{loc [{$near [{$geometry [{type Point} {coordinates [1 2]}]} {$maxDistance 100}]}]} "+
+		"{area [{$geoWithin [{$geometry [{type Polygon} {coordinates [[[0 0] [0 1] [1 1] [0 0]]]}]}]}]} "+
+		"{line [{$geoIntersects [{$geometry [{type LineString} {coordinates [[0 0] [1 1]]}]}]}]}]" {
+		t.Error(doc)
+		return
+	}
+	//
+	//struct tag
+	query = For(user{}).Eq("Name", "x").Gte("Address.City", "c").Ne("Tags.City", "y")
+	if fmt.Sprintf("%v", query.D()) != "[{n x} {addr.city [{$gte c}]} {tags.city [{$ne y}]}]" {
+		t.Error(query.D())
+		return
+	}
+	//
+	//error
+	for i, query := range []*Query{
+		Eq("$a", 1),
+		Eq("a ", 1),
+		Eq("", 1),
+		Eq("a", 1).Eq("a", 2),
+		Eq("a", 1).Gt("a", 2),
+		Gt("a", 1).Gt("a", 2),
+		Gt("a", 1).Eq("a", 2),
+		New().Op("a", "$gte ", 1),
+		In("a", 1),
+		Nin("a", nil),
+		New().All("a", "x"),
+		Or(),
+		Or(Eq("a", 1)).Or(Eq("b", 1)),
+		Or(Eq("$a", 1)),
+		New().ElemMatch("a", Eq("$b", 1)),
+		For(user{}).Eq("Nick", 1),
+		For(user{}).Eq("Name.X", 1),
+		For(1).Eq("Name", 1),
+	} {
+		if query.Err() == nil {
+			t.Errorf("%v not error", i)
+			return
+		}
+		if _, err := bson.Marshal(query); err == nil {
+			t.Errorf("%v not error", i)
+			return
+		}
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("not panic")
+			}
+		}()
+		Eq("$a", 1).D()
+	}()
+}
+
+func TestBuilderOnCollection(t *testing.T) {
+	pool := mongoc.NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	for i := 0; i < 5; i++ {
+		col.Insert(bson.M{"_id": fmt.Sprintf("builder-%v", i), "age": i})
+	}
+	var users []*user
+	err := col.Find(For(user{}).Gte("Age", 3), nil, 0, 0, &users)
+	if err != nil || len(users) != 2 {
+		t.Errorf("users:%v,err:%v", users, err)
+		return
+	}
+	_, err = col.UpdateOne(Eq("_id", "builder-0"), u.For(user{}).Set("Name", "x").Inc("Age", 10), nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	bulk := col.NewBulk(false)
+	bulk.Update(In("_id", []string{"builder-1", "builder-2"}), u.Set("n", "y"), false)
+	bulk.Remove(Eq("_id", "builder-4"))
+	_, err = bulk.Execute()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	count, err := col.Count(Or(Eq("n", "x"), Eq("n", "y")), 0, 0)
+	if err != nil || count != 3 {
+		t.Errorf("count:%v,err:%v", count, err)
+		return
+	}
+	_, err = col.UpdateOne(Eq("_id", "builder-0"), u.New(), nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
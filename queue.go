@@ -0,0 +1,296 @@
+package mongoc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ErrDuplicateJob is the error when the job with same dedupe key is already in queue.
+var ErrDuplicateJob = fmt.Errorf("duplicate job")
+
+//ErrJobLost is the error when the job is claimed by other worker after visibility timeout.
+var ErrJobLost = fmt.Errorf("job lost")
+
+//errNamespaceExists is the server error code when the collection is already exists.
+const errNamespaceExists = 48
+
+//Job is the job document in queue.
+type Job struct {
+	ID        interface{} `bson:"_id"`
+	Payload   bson.Raw    `bson:"payload"` //the payload, it can be unmarshaled by Payload.Unmarshal.
+	Priority  int         `bson:"priority"`
+	Dedupe    string      `bson:"dedupe,omitempty"`
+	Visible   time.Time   `bson:"visible"` //the time when job can be dequeued.
+	Attempts  int         `bson:"attempts"`
+	Receipt   string      `bson:"receipt,omitempty"` //the claim receipt of current worker.
+	Created   time.Time   `bson:"created"`
+	LastError string      `bson:"error,omitempty"`
+	Failed    time.Time   `bson:"failed,omitempty"` //the time when job is moved to dead letter.
+}
+
+//EnqueueOptions is the options for Queue.Enqueue.
+type EnqueueOptions struct {
+	Priority int           //the higher priority job is dequeued first.
+	Delay    time.Duration //the job is not visible until the delay is passed.
+	Dedupe   string        //the dedupe key, the job is not enqueued when the job with same key is in queue.
+}
+
+//Queue is the durable job queue on collection, following:
+//
+//	queue, err := col.NewQueue()
+//	queue.Enqueue(bson.M{"mail": "x"}, &EnqueueOptions{Priority: 1})
+//	job, err := queue.DequeueWait(ctx)
+//	if err == nil {
+//		err = send(job)
+//		if err == nil {
+//			queue.Ack(job)
+//		} else {
+//			queue.Nack(job, err, time.Minute)
+//		}
+//	}
+//
+//the job is claimed by findAndModify and it will be visible again after visibility timeout if it is not acked,
+//and it is moved to dead letter collection after MaxAttempts.
+//the waiting worker is notified by tailing the capped notify collection.
+type Queue struct {
+	C            *Collection
+	DeadLetter   *Collection   //the dead letter collection, default is name_dead.
+	Notify       *Collection   //the capped notify collection, default is name_notify.
+	Visibility   time.Duration //the visibility timeout of dequeued job, default is 30s.
+	MaxAttempts  int           //the max attempts before moving to dead letter, default is 5.
+	PollInterval time.Duration //the interval to poll delayed job on waiting, default is 1s.
+}
+
+//NewQueue will create the queue on collection, the indexes and notify collection is checked.
+func (c *Collection) NewQueue() (queue *Queue, err error) {
+	queue = &Queue{
+		C: c,
+		DeadLetter: &Collection{
+			Name:   c.Name + "_dead",
+			DbName: c.DbName,
+			Pool:   c.Pool,
+		},
+		Notify: &Collection{
+			Name:   c.Name + "_notify",
+			DbName: c.DbName,
+			Pool:   c.Pool,
+		},
+		Visibility:   30 * time.Second,
+		MaxAttempts:  5,
+		PollInterval: time.Second,
+	}
+	err = c.CheckIndex(false, &Index{
+		Key:  []string{"-priority", "visible"},
+		Name: "priority_visible",
+	}, &Index{
+		Key:                     []string{"dedupe"},
+		Name:                    "dedupe",
+		Unique:                  true,
+		PartialFilterExpression: bson.M{"dedupe": bson.M{"$exists": true}},
+	})
+	if err != nil {
+		return
+	}
+	var client = c.Pool.Pop()
+	defer client.Close()
+	err = client.Execute(c.DbName, bson.D{
+		{
+			Name:  "create",
+			Value: queue.Notify.Name,
+		},
+		{
+			Name:  "capped",
+			Value: true,
+		},
+		{
+			Name:  "size",
+			Value: 1024 * 1024,
+		},
+	}, nil, &bson.M{})
+	if berr, ok := err.(*BSONError); ok && berr.Code == errNamespaceExists {
+		err = nil
+	}
+	return
+}
+
+//Enqueue will add the job to queue and return the job id, the opts can be nil.
+//if the job with same dedupe key is in queue, it return the id of existing job and ErrDuplicateJob.
+func (q *Queue) Enqueue(payload interface{}, opts *EnqueueOptions) (id interface{}, err error) {
+	if opts == nil {
+		opts = &EnqueueOptions{}
+	}
+	var now = time.Now()
+	var doc = bson.M{
+		"_id":      bson.NewObjectId(),
+		"payload":  payload,
+		"priority": opts.Priority,
+		"visible":  now.Add(opts.Delay),
+		"attempts": 0,
+		"created":  now,
+	}
+	if len(opts.Dedupe) < 1 {
+		err = q.C.Insert(doc)
+		id = doc["_id"]
+	} else {
+		var job = &Job{}
+		var changed *Changed
+		changed, err = q.C.FindAndModify(bson.M{"dedupe": opts.Dedupe}, nil, bson.M{"$setOnInsert": doc}, nil, true, true, job)
+		if berr, ok := err.(*BSONError); ok && berr.Code == ErrDuplicateKey { //upsert concurrently.
+			err = ErrDuplicateJob
+		}
+		if err != nil {
+			return
+		}
+		id = job.ID
+		if changed.Upserted == nil {
+			err = ErrDuplicateJob
+		}
+	}
+	if err == nil && opts.Delay <= 0 {
+		if nerr := q.Notify.Insert(bson.M{"_id": bson.NewObjectId()}); nerr != nil {
+			warnLog("queue(%v) notify fail with %v", q.C.Name, nerr)
+		}
+	}
+	return
+}
+
+//Dequeue will claim one visible job by priority, it return ErrNotFound when queue is empty.
+//the job which attempts is more than MaxAttempts is moved to dead letter.
+func (q *Queue) Dequeue() (job *Job, err error) {
+	for {
+		var now = time.Now()
+		job = &Job{}
+		_, err = q.C.FindAndModify(bson.M{
+			"visible": bson.M{"$lte": now},
+		}, ParseSorted("-priority", "visible"), bson.M{
+			"$set": bson.M{
+				"visible": now.Add(q.Visibility),
+				"receipt": bson.NewObjectId().Hex(),
+			},
+			"$inc": bson.M{
+				"attempts": 1,
+			},
+		}, nil, false, true, job)
+		if err != nil {
+			return
+		}
+		if job.ID == nil {
+			err = ErrNotFound
+			return
+		}
+		if job.Attempts <= q.MaxAttempts {
+			return
+		}
+		job.LastError = "attempts exceeded"
+		err = q.dead(job)
+		if err != nil && err != ErrJobLost {
+			return
+		}
+	}
+}
+
+//DequeueWait will wait and claim one visible job until context is done.
+func (q *Queue) DequeueWait(ctx context.Context) (job *Job, err error) {
+	job, err = q.Dequeue()
+	if err != ErrNotFound {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var notified = make(chan int, 1)
+	go func() {
+		var tail = q.Notify.Tail(nil, &TailOptions{
+			Context:      ctx,
+			ResumeAfter:  bson.NewObjectId(),
+			MaxAwaitTime: q.PollInterval,
+		})
+		defer tail.Close()
+		for tail.Next(nil) {
+			select {
+			case notified <- 1:
+			default:
+			}
+		}
+	}()
+	var ticker = time.NewTicker(q.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-notified:
+		case <-ticker.C:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		job, err = q.Dequeue()
+		if err != ErrNotFound {
+			return
+		}
+	}
+}
+
+//Extend will extend the visibility timeout of job, it return ErrJobLost when job is claimed by other.
+func (q *Queue) Extend(job *Job, visibility time.Duration) (err error) {
+	var visible = time.Now().Add(visibility)
+	_, err = q.C.UpdateOne(bson.M{"_id": job.ID, "receipt": job.Receipt}, bson.M{
+		"$set": bson.M{
+			"visible": visible,
+		},
+	}, nil)
+	if err == ErrNotFound {
+		err = ErrJobLost
+	}
+	if err == nil {
+		job.Visible = visible
+	}
+	return
+}
+
+//Ack will remove the done job from queue, it return ErrJobLost when job is claimed by other.
+func (q *Queue) Ack(job *Job) (err error) {
+	result, err := q.C.DeleteOne(bson.M{"_id": job.ID, "receipt": job.Receipt}, nil)
+	if err == nil && result.Deleted < 1 {
+		err = ErrJobLost
+	}
+	return
+}
+
+//Nack will release the failed job to be retried after delay, the job is moved to dead letter
+//when attempts reach MaxAttempts. it return ErrJobLost when job is claimed by other.
+func (q *Queue) Nack(job *Job, cause error, delay time.Duration) (err error) {
+	if cause != nil {
+		job.LastError = cause.Error()
+	}
+	if job.Attempts >= q.MaxAttempts {
+		return q.dead(job)
+	}
+	_, err = q.C.UpdateOne(bson.M{"_id": job.ID, "receipt": job.Receipt}, bson.M{
+		"$set": bson.M{
+			"visible": time.Now().Add(delay),
+			"error":   job.LastError,
+		},
+		"$unset": bson.M{
+			"receipt": 1,
+		},
+	}, nil)
+	if err == ErrNotFound {
+		err = ErrJobLost
+	}
+	return
+}
+
+//dead will move the job to dead letter collection.
+func (q *Queue) dead(job *Job) (err error) {
+	job.Failed = time.Now()
+	err = q.DeadLetter.Insert(job)
+	if berr, ok := err.(*BSONError); ok && berr.Code == ErrDuplicateKey { //moved before.
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	return q.Ack(job)
+}
@@ -0,0 +1,151 @@
+package mongoc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestQueue(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_queue")
+	col.RemoveAll(nil)
+	pool.C("test", "mongoc_queue_dead").RemoveAll(nil)
+	queue, err := col.NewQueue()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	queue.MaxAttempts = 2
+	queue.PollInterval = 100 * time.Millisecond
+	//
+	//enqueue
+	_, err = queue.Enqueue(bson.M{"n": 1}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = queue.Enqueue(bson.M{"n": 2}, &EnqueueOptions{Priority: 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	id, err := queue.Enqueue(bson.M{"n": 3}, &EnqueueOptions{Delay: 200 * time.Millisecond, Dedupe: "x"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	id2, err := queue.Enqueue(bson.M{"n": 4}, &EnqueueOptions{Dedupe: "x"})
+	if err != ErrDuplicateJob || id2 != id {
+		t.Errorf("id:%v,err:%v", id2, err)
+		return
+	}
+	//
+	//dequeue by priority
+	var payloads []int
+	for i := 0; i < 2; i++ {
+		job, err := queue.Dequeue()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		payload := bson.M{}
+		job.Payload.Unmarshal(&payload)
+		payloads = append(payloads, payload["n"].(int))
+		err = queue.Ack(job)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if queue.Ack(job) != ErrJobLost {
+			t.Error("not lost")
+			return
+		}
+	}
+	if fmt.Sprintf("%v", payloads) != "[2 1]" {
+		t.Errorf("payloads:%v", payloads)
+		return
+	}
+	_, err = queue.Dequeue()
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	//
+	//wait for delayed job
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	job, err := queue.DequeueWait(ctx)
+	if err != nil || job.ID != id || job.Attempts != 1 {
+		t.Errorf("job:%v,err:%v", job, err)
+		return
+	}
+	//
+	//nack and dead letter
+	err = queue.Nack(job, fmt.Errorf("fail"), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	job, err = queue.Dequeue()
+	if err != nil || job.Attempts != 2 || job.LastError != "fail" {
+		t.Errorf("job:%v,err:%v", job, err)
+		return
+	}
+	err = queue.Nack(job, fmt.Errorf("fail2"), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	dead := &Job{}
+	err = queue.DeadLetter.FindOne(bson.M{"_id": id}, nil, dead)
+	if err != nil || dead.LastError != "fail2" {
+		t.Errorf("dead:%v,err:%v", dead, err)
+		return
+	}
+	//
+	//visibility timeout
+	queue.Visibility = 100 * time.Millisecond
+	queue.Enqueue(bson.M{"n": 5}, nil)
+	job, err = queue.Dequeue()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	time.Sleep(200 * time.Millisecond)
+	job2, err := queue.Dequeue()
+	if err != nil || job2.ID != job.ID {
+		t.Errorf("job:%v,err:%v", job2, err)
+		return
+	}
+	if queue.Extend(job, time.Second) != ErrJobLost || queue.Nack(job, nil, 0) != ErrJobLost {
+		t.Error("not lost")
+		return
+	}
+	//
+	//notify
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		queue.Enqueue(bson.M{"n": 6}, nil)
+	}()
+	queue.Ack(job2)
+	queue.PollInterval = time.Minute
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel2()
+	job, err = queue.DequeueWait(ctx2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	queue.Ack(job)
+	//
+	//error
+	_, err = queue.Enqueue(TestQueue, nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
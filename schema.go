@@ -0,0 +1,248 @@
+package mongoc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+var (
+	typeTime      = reflect.TypeOf(time.Time{})
+	typeObjectID  = reflect.TypeOf(bson.ObjectId(""))
+	typeTimestamp = reflect.TypeOf(bson.MongoTimestamp(0))
+	typeBsonRaw   = reflect.TypeOf(bson.Raw{})
+	typeBytes     = reflect.TypeOf([]byte{})
+)
+
+//SchemaFor will derive the validator document {$jsonSchema:schema} from the struct by bson tag and schema tag, following:
+//
+//	Name  string   `bson:"name" schema:"minLength=1,maxLength=32"`
+//	Type  string   `bson:"type" schema:"enum=a|b|c,description=the type"`
+//	Age   int      `bson:"age" schema:"min=0,max=200"`
+//	Tags  []string `bson:"tags" schema:"maxItems=10"`
+//	Extra *Extra   `bson:"extra,omitempty"`
+//
+//the field is required when it is not omitempty, the pointer/interface field allows null,
+//and the required can be set/unset by schema:"required"/schema:"optional".
+//it return error when v is not struct, the field type is not supported or tag is invalid.
+//for more https://docs.mongodb.com/manual/reference/operator/query/jsonSchema/
+func SchemaFor(v interface{}) (validator bson.M, err error) {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		err = fmt.Errorf("the schema must be derived from struct, but %v", typ)
+		return
+	}
+	schema, err := schemaOf(typ, map[reflect.Type]bool{})
+	if err != nil {
+		return
+	}
+	validator = bson.M{
+		"$jsonSchema": schema,
+	}
+	return
+}
+
+func schemaOf(typ reflect.Type, visited map[reflect.Type]bool) (schema bson.M, err error) {
+	switch typ {
+	case typeTime:
+		return bson.M{"bsonType": "date"}, nil
+	case typeObjectID:
+		return bson.M{"bsonType": "objectId"}, nil
+	case typeTimestamp:
+		return bson.M{"bsonType": "timestamp"}, nil
+	case typeBytes:
+		return bson.M{"bsonType": "binData"}, nil
+	case typeBsonRaw:
+		return bson.M{}, nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		schema, err = schemaOf(typ.Elem(), visited)
+		if err == nil {
+			allowNull(schema)
+		}
+	case reflect.Interface:
+		schema = bson.M{}
+	case reflect.String:
+		schema = bson.M{"bsonType": "string"}
+	case reflect.Bool:
+		schema = bson.M{"bsonType": "bool"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		schema = bson.M{"bsonType": "int"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		schema = bson.M{"bsonType": []string{"int", "long"}}
+	case reflect.Float32, reflect.Float64:
+		schema = bson.M{"bsonType": "double"}
+	case reflect.Slice, reflect.Array:
+		var items bson.M
+		items, err = schemaOf(typ.Elem(), visited)
+		schema = bson.M{
+			"bsonType": "array",
+			"items":    items,
+		}
+	case reflect.Map:
+		var values bson.M
+		values, err = schemaOf(typ.Elem(), visited)
+		schema = bson.M{
+			"bsonType":             "object",
+			"additionalProperties": values,
+		}
+	case reflect.Struct:
+		schema = bson.M{"bsonType": "object"}
+		if visited[typ] { //recursive type.
+			return
+		}
+		visited[typ] = true
+		properties, required := bson.M{}, []string{}
+		err = schemaFields(typ, visited, properties, &required)
+		delete(visited, typ)
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	default:
+		err = fmt.Errorf("the type %v is not supported by schema", typ)
+	}
+	return
+}
+
+func schemaFields(typ reflect.Type, visited map[reflect.Type]bool, properties bson.M, required *[]string) (err error) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, inline, skip := FieldName(field)
+		if skip {
+			continue
+		}
+		if inline && field.Type.Kind() == reflect.Struct {
+			err = schemaFields(field.Type, visited, properties, required)
+			if err != nil {
+				return
+			}
+			continue
+		}
+		var schema bson.M
+		schema, err = schemaOf(field.Type, visited)
+		if err != nil {
+			err = fmt.Errorf("%v on %v.%v", err, typ.Name(), field.Name)
+			return
+		}
+		isRequired := !strings.Contains(field.Tag.Get("bson"), ",omitempty")
+		for _, item := range strings.Split(field.Tag.Get("schema"), ",") {
+			if len(item) < 1 {
+				continue
+			}
+			key, value, _ := strings.Cut(item, "=")
+			switch key {
+			case "required":
+				isRequired = true
+			case "optional":
+				isRequired = false
+			case "description", "pattern":
+				schema[key] = value
+			case "enum":
+				var enum = []interface{}{}
+				for _, one := range strings.Split(value, "|") {
+					var parsed interface{}
+					parsed, err = schemaValue(field, key, one)
+					if err != nil {
+						return
+					}
+					enum = append(enum, parsed)
+				}
+				schema["enum"] = enum
+			case "min", "max":
+				var parsed interface{}
+				parsed, err = schemaValue(field, key, value)
+				if err != nil {
+					return
+				}
+				schema[map[string]string{"min": "minimum", "max": "maximum"}[key]] = parsed
+			case "minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+				n, perr := strconv.Atoi(value)
+				if perr != nil {
+					err = fmt.Errorf("invalid schema tag(%v) on %v.%v", item, typ.Name(), field.Name)
+					return
+				}
+				schema[key] = n
+			default:
+				err = fmt.Errorf("unknown schema tag(%v) on %v.%v", item, typ.Name(), field.Name)
+				return
+			}
+		}
+		properties[name] = schema
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+	return
+}
+
+//schemaValue will parse the tag value by field kind.
+func schemaValue(field reflect.StructField, key, value string) (parsed interface{}, err error) {
+	typ := field.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err = strconv.ParseInt(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		parsed, err = strconv.ParseFloat(value, 64)
+	default:
+		parsed = value
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid schema %v(%v) on %v", key, value, field.Name)
+	}
+	return
+}
+
+//allowNull will add null to bsonType of schema.
+func allowNull(schema bson.M) {
+	switch t := schema["bsonType"].(type) {
+	case string:
+		schema["bsonType"] = []string{t, "null"}
+	case []string:
+		schema["bsonType"] = append(append([]string{}, t...), "null")
+	}
+}
+
+//ApplyValidator will apply the validator to collection by collMod, the collection is created when it is not exists.
+//the level is off/strict/moderate and the action is error/warn, the empty is server default.
+//for more https://docs.mongodb.com/manual/core/schema-validation/
+func (c *Collection) ApplyValidator(validator interface{}, level, action string) (err error) {
+	var client = c.Pool.Pop()
+	defer client.Close()
+	var command = func(name string) bson.D {
+		var cmd = bson.D{
+			{
+				Name:  name,
+				Value: c.Name,
+			},
+			{
+				Name:  "validator",
+				Value: validator,
+			},
+		}
+		if len(level) > 0 {
+			cmd = append(cmd, bson.DocElem{Name: "validationLevel", Value: level})
+		}
+		if len(action) > 0 {
+			cmd = append(cmd, bson.DocElem{Name: "validationAction", Value: action})
+		}
+		return cmd
+	}
+	err = client.Execute(c.DbName, command("collMod"), nil, &bson.M{})
+	if berr, ok := err.(*BSONError); ok && berr.IsCollectionNotExist() {
+		err = client.Execute(c.DbName, command("create"), nil, &bson.M{})
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package mongoc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+type schemaExtra struct {
+	Note string `bson:"note" schema:"pattern=^n"`
+}
+
+type schemaNode struct {
+	Name     string        `bson:"name"`
+	Children []*schemaNode `bson:"children,omitempty"`
+}
+
+type schemaUser struct {
+	Document `bson:",inline"`
+	Name     string              `bson:"name" schema:"minLength=1,maxLength=32"`
+	Type     string              `bson:"type" schema:"enum=a|b,description=the type"`
+	Age      int                 `bson:"age" schema:"min=0,max=200"`
+	Level    int8                `bson:"level" schema:"enum=1|2"`
+	Score    float64             `bson:"score" schema:"min=0.5,optional"`
+	Tags     []string            `bson:"tags" schema:"maxItems=10"`
+	Attrs    map[string]int      `bson:"attrs,omitempty"`
+	Extra    *schemaExtra        `bson:"extra,omitempty" schema:"required"`
+	Any      interface{}         `bson:"any,omitempty"`
+	Data     []byte              `bson:"data,omitempty"`
+	Tree     *schemaNode         `bson:"tree,omitempty"`
+	TS       bson.MongoTimestamp `bson:"ts,omitempty"`
+	Login    time.Time           `bson:"login,omitempty"`
+	Hidden   string              `bson:"-"`
+	private  string
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := SchemaFor(&schemaUser{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	bys, _ := json.Marshal(schema)
+	expected := `{"$jsonSchema":{"bsonType":"object","properties":{"_id":{"bsonType":"objectId"},"age":{"bsonType":["int","long"],"maximum":200,"minimum":0},` +
+		`"any":{},"attrs":{"additionalProperties":{"bsonType":["int","long"]},"bsonType":"object"},"created":{"bsonType":"date"},"data":{"bsonType":"binData"},` +
+		`"extra":{"bsonType":["object","null"],"properties":{"note":{"bsonType":"string","pattern":"^n"}},"required":["note"]},"level":{"bsonType":"int","enum":[1,2]},` +
+		`"login":{"bsonType":"date"},"name":{"bsonType":"string","maxLength":32,"minLength":1},"score":{"bsonType":"double","minimum":0.5},"tags":{"bsonType":"array","items":{"bsonType":"string"},"maxItems":10},` +
+		`"tree":{"bsonType":["object","null"],"properties":{"children":{"bsonType":"array","items":{"bsonType":["object","null"]}},"name":{"bsonType":"string"}},"required":["name"]},` +
+		`"ts":{"bsonType":"timestamp"},"type":{"bsonType":"string","description":"the type","enum":["a","b"]},"updated":{"bsonType":"date"}},` +
+		`"required":["created","updated","name","type","age","level","tags","extra"]}}`
+	if string(bys) != expected {
+		t.Errorf("schema:%s", bys)
+		return
+	}
+	//
+	//error
+	for _, v := range []interface{}{
+		1,
+		&struct {
+			A int `schema:"xx"`
+		}{},
+		&struct {
+			A int `schema:"min=x"`
+		}{},
+		&struct {
+			A float64 `schema:"max=x"`
+		}{},
+		&struct {
+			A string `schema:"minLength=x"`
+		}{},
+		&struct {
+			A chan int
+		}{},
+	} {
+		_, err = SchemaFor(v)
+		if err == nil {
+			t.Errorf("not error on %v", v)
+			return
+		}
+	}
+}
+
+func TestApplyValidator(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_schema")
+	col.Drop()
+	schema, err := SchemaFor(&schemaExtra{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.ApplyValidator(schema, "strict", "error")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.Insert(bson.M{"note": "x"})
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	err = col.Insert(bson.M{"note": "nx"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.ApplyValidator(bson.M{}, "off", "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.Insert(bson.M{"note": "x"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.ApplyValidator(bson.M{}, "xx", "")
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
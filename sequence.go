@@ -0,0 +1,75 @@
+package mongoc
+
+import (
+	"sync"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//Sequence is the atomic sequence generator by $inc on findAndModify, the counter document is {_id:name,seq:allocated count}.
+//
+//the value is Start+index*Step, so the Start/Step must be same for all generator of one name,
+//and the Block values are reserved per round trip and served locally,
+//so the values is not continuous when the process is restarted with reserved values.
+//it is safe for concurrent goroutines and processes.
+type Sequence struct {
+	C     *Collection
+	Name  string
+	Start int64 //the first value, default is 1.
+	Step  int64 //the step between values, default is 1.
+	Block int64 //the count of values reserved per round trip, default is 1.
+	//
+	lck   sync.Mutex
+	next  int64
+	limit int64
+}
+
+type sequenceDoc struct {
+	Seq int64 `bson:"seq"`
+}
+
+//Sequence will create the sequence generator by name on collection.
+func (c *Collection) Sequence(name string) *Sequence {
+	return &Sequence{
+		C:     c,
+		Name:  name,
+		Start: 1,
+		Step:  1,
+		Block: 1,
+	}
+}
+
+//Sequence will create the sequence generator by name on sequence collection of database.
+func (p *Pool) Sequence(dbname, name string) *Sequence {
+	return p.C(dbname, "sequence").Sequence(name)
+}
+
+//Next return the next value of sequence.
+func (s *Sequence) Next() (value int64, err error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+	if s.next >= s.limit {
+		err = s.reserve()
+		if err != nil {
+			return
+		}
+	}
+	value = s.Start + s.next*s.Step
+	s.next++
+	return
+}
+
+//reserve will allocate one block of values, it must be called with lock.
+func (s *Sequence) reserve() (err error) {
+	var block = s.Block
+	if block < 1 {
+		block = 1
+	}
+	var doc = &sequenceDoc{}
+	_, err = s.C.FindAndModify(bson.M{"_id": s.Name}, nil, bson.M{"$inc": bson.M{"seq": block}}, nil, true, true, doc)
+	if err != nil {
+		return
+	}
+	s.next, s.limit = doc.Seq-block, doc.Seq
+	return
+}
@@ -0,0 +1,66 @@
+package mongoc
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	pool.C("test", "sequence").RemoveAll(nil)
+	seq := pool.Sequence("test", "order")
+	for i := int64(1); i < 4; i++ {
+		value, err := seq.Next()
+		if err != nil || value != i {
+			t.Errorf("value:%v,err:%v", value, err)
+			return
+		}
+	}
+	//
+	//block and step
+	seqA := pool.Sequence("test", "block")
+	seqA.Start, seqA.Step, seqA.Block = 1000, 10, 5
+	seqB := pool.Sequence("test", "block")
+	seqB.Start, seqB.Step, seqB.Block = 1000, 10, 5
+	value, _ := seqA.Next()
+	if value != 1000 {
+		t.Errorf("value:%v", value)
+		return
+	}
+	value, _ = seqB.Next()
+	if value != 1050 {
+		t.Errorf("value:%v", value)
+		return
+	}
+	value, _ = seqA.Next()
+	if value != 1010 {
+		t.Errorf("value:%v", value)
+		return
+	}
+	//
+	//concurrent
+	var lck sync.Mutex
+	var waiter sync.WaitGroup
+	values := map[int64]bool{}
+	for i := 0; i < 10; i++ {
+		waiter.Add(1)
+		go func(seq *Sequence) {
+			defer waiter.Done()
+			for j := 0; j < 50; j++ {
+				value, err := seq.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				lck.Lock()
+				values[value] = true
+				lck.Unlock()
+			}
+		}([]*Sequence{seqA, seqB}[i%2])
+	}
+	waiter.Wait()
+	if len(values) != 500 {
+		t.Errorf("values:%v", len(values))
+		return
+	}
+}
@@ -0,0 +1,171 @@
+package mongoc
+
+/*
+#include <mongoc.h>
+*/
+import "C"
+import (
+	"fmt"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ErrSessionNotSupported is returned when the operation can't be run in session.
+var ErrSessionNotSupported = fmt.Errorf("not supported in session")
+
+//Session is the wrapper of C.mongoc_client_session_t, it is bound to one client of pool, following:
+//
+//	session, err := pool.StartSession()
+//	defer session.End()
+//	err = session.StartTransaction()
+//	_, err = session.Pool().C("test", "order").UpdateOne(bson.M{"_id": id}, bson.M{"$set": bson.M{"status": 1}}, nil)
+//	err = session.CommitTransaction()
+//
+//the operation by Session.Pool is run in session, the GridFS and Tail return ErrSessionNotSupported.
+//the session pool only has one client, so the operation must not be nested or run concurrently,
+//like using the pool in TypedCollection.Each call, the Pop will panic when the client is in use.
+//Warning: End needed after used.
+type Session struct {
+	raw    *C.mongoc_client_session_t
+	parent *Pool
+	pool   *Pool
+}
+
+//StartSession will pop one client from pool and start the session on it, the client is pushed back on End.
+func (p *Pool) StartSession() (session *Session, err error) {
+	var client = p.Pop()
+	var berr C.bson_error_t
+	var raw = C.mongoc_client_start_session(client.raw, nil, &berr)
+	if raw == nil {
+		err = parseBSONError(&berr)
+		client.LastError = err
+		client.Close()
+		return
+	}
+	session = &Session{
+		raw:    raw,
+		parent: p,
+		pool: &Pool{
+			URI:     p.URI,
+			pool:    make(chan *Client, 1),
+			ping:    make(chan *Client, 1),
+			max:     make(chan int, 1),
+			ErrVer:  p.ErrVer,
+			maxSize: 1,
+			Timeout: p.Timeout,
+			Err:     &sessionErrorFilter{},
+			bound:   true,
+		},
+	}
+	client.Pool = session.pool
+	client.session = session
+	session.pool.pool <- client
+	return
+}
+
+//Pool return the pool which only has the session bound client, the operation by it is run in session.
+//the operation is waiting for other operation done, so the cursor must not be read when running other operation.
+func (s *Session) Pool() *Pool {
+	return s.pool
+}
+
+//StartTransaction will start the transaction on session.
+func (s *Session) StartTransaction() (err error) {
+	var berr C.bson_error_t
+	if !C.mongoc_client_session_start_transaction(s.raw, nil, &berr) {
+		err = parseBSONError(&berr)
+	}
+	return
+}
+
+//CommitTransaction will commit the transaction on session.
+func (s *Session) CommitTransaction() (err error) {
+	var reply C.bson_t
+	var berr C.bson_error_t
+	if !C.mongoc_client_session_commit_transaction(s.raw, &reply, &berr) {
+		err = parseBSONError(&berr)
+	}
+	C.bson_destroy(&reply)
+	return
+}
+
+//AbortTransaction will abort the transaction on session.
+func (s *Session) AbortTransaction() (err error) {
+	var berr C.bson_error_t
+	if !C.mongoc_client_session_abort_transaction(s.raw, &berr) {
+		err = parseBSONError(&berr)
+	}
+	return
+}
+
+//InTransaction return true when the transaction is started and not committed or aborted.
+func (s *Session) InTransaction() bool {
+	return bool(C.mongoc_client_session_in_transaction(s.raw))
+}
+
+//End will end the session and push the client back to pool, the running transaction is aborted.
+func (s *Session) End() {
+	var client = s.pool.Pop()
+	C.mongoc_client_session_destroy(s.raw)
+	client.session = nil
+	client.Pool = s.parent
+	client.Close()
+}
+
+//append will append the session id to command options.
+func (s *Session) append(opts *C.bson_t) (err error) {
+	var berr C.bson_error_t
+	if !C.mongoc_client_session_append(s.raw, opts, &berr) {
+		err = parseBSONError(&berr)
+	}
+	return
+}
+
+//sessionErrorFilter will keep the session bound client in session pool on error.
+type sessionErrorFilter struct {
+	DefaultErrorFilter
+}
+
+func (s *sessionErrorFilter) IsNormalError(err error) bool {
+	return true
+}
+
+type isMasterReply struct {
+	SetName        string `bson:"setName"`
+	Msg            string `bson:"msg"`
+	MaxWireVersion int    `bson:"maxWireVersion"`
+}
+
+//SupportTransaction return true when the server is replica set of 4.0 or later or sharded cluster of 4.2 or later.
+func (p *Pool) SupportTransaction() (supported bool, err error) {
+	var reply = &isMasterReply{}
+	err = p.Execute("admin", bson.M{"isMaster": 1}, nil, reply)
+	if err != nil {
+		return
+	}
+	supported = (len(reply.SetName) > 0 && reply.MaxWireVersion >= 7) || (reply.Msg == "isdbgrid" && reply.MaxWireVersion >= 8)
+	return
+}
+
+//WithTransaction will run the call in transaction on new session, the transaction is committed when call return nil,
+//otherwise it is aborted and the call error is returned.
+func (p *Pool) WithTransaction(call func(pool *Pool) error) (err error) {
+	session, err := p.StartSession()
+	if err != nil {
+		return
+	}
+	defer session.End()
+	err = session.StartTransaction()
+	if err != nil {
+		return
+	}
+	err = call(session.Pool())
+	if err != nil {
+		if aerr := session.AbortTransaction(); aerr != nil {
+			err = fmt.Errorf("%v, and abort transaction fail with %v", err, aerr)
+		}
+		return
+	}
+	err = session.CommitTransaction()
+	return
+}
@@ -0,0 +1,187 @@
+package mongoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestSession(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	supported, err := pool.SupportTransaction()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !supported {
+		t.Log("transaction is not supported")
+		return
+	}
+	col := pool.C("test", "mongoc_session")
+	col.Drop()
+	err = pool.Execute("test", bson.M{"create": "mongoc_session"}, nil, &bson.M{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//
+	//commit
+	err = pool.WithTransaction(func(pool *Pool) (err error) {
+		tcol := pool.C("test", "mongoc_session")
+		err = tcol.Insert(bson.M{"_id": 1}, bson.M{"_id": 2})
+		if err != nil {
+			return
+		}
+		_, err = tcol.UpdateOne(bson.M{"_id": 1}, bson.M{"$set": bson.M{"v": 1}}, nil)
+		if err != nil {
+			return
+		}
+		var found = bson.M{}
+		err = tcol.FindOne(bson.M{"_id": 1}, nil, &found)
+		if err == nil && found["v"] != 1 {
+			err = fmt.Errorf("found:%v", found)
+		}
+		if count, _ := col.CountDocuments(nil, nil); count != 0 {
+			err = fmt.Errorf("count:%v", count)
+		}
+		return
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if count, _ := col.CountDocuments(nil, nil); count != 2 {
+		t.Errorf("count:%v", count)
+		return
+	}
+	//
+	//abort
+	err = pool.WithTransaction(func(pool *Pool) (err error) {
+		_, err = pool.C("test", "mongoc_session").DeleteMany(nil, nil)
+		if err != nil {
+			return
+		}
+		return fmt.Errorf("abort")
+	})
+	if err == nil || err.Error() != "abort" {
+		t.Error(err)
+		return
+	}
+	if count, _ := col.CountDocuments(nil, nil); count != 2 {
+		t.Errorf("count:%v", count)
+		return
+	}
+	//
+	//session
+	session, err := pool.StartSession()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer session.End()
+	err = session.StartTransaction()
+	if err != nil || !session.InTransaction() {
+		t.Error(err)
+		return
+	}
+	_, err = session.Pool().C("test", "mongoc_session").DeleteOne(bson.M{"_id": 2}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = session.CommitTransaction()
+	if err != nil || session.InTransaction() {
+		t.Error(err)
+		return
+	}
+	if count, _ := col.CountDocuments(nil, nil); count != 1 {
+		t.Errorf("count:%v", count)
+		return
+	}
+	//
+	//legacy
+	scol := session.Pool().C("test", "mongoc_session")
+	if count, err := scol.Count(nil, 0, 0); err != nil || count != 1 {
+		t.Errorf("count:%v,%v", count, err)
+		return
+	}
+	var stats = bson.M{}
+	err = scol.Stats(bson.M{"scale": 1}, &stats)
+	if err != nil || stats["count"] != 1 {
+		t.Errorf("stats:%v,%v", stats, err)
+		return
+	}
+	err = session.Pool().GridFS("test", "mongoc_session").Delete(1)
+	if err != ErrSessionNotSupported {
+		t.Error(err)
+		return
+	}
+}
+
+func TestSessionNestedPop(t *testing.T) {
+	pool := &Pool{
+		pool:  make(chan *Client, 1),
+		bound: true,
+	}
+	pool.pool <- &Client{}
+	client := pool.Pop()
+	defer func() {
+		if recover() == nil {
+			t.Error("not panic")
+		}
+		pool.pool <- client
+	}()
+	pool.Pop()
+}
+
+func TestLegacyQuery(t *testing.T) {
+	for _, c := range []struct {
+		query  interface{}
+		filter string
+		sort   string
+	}{
+		{nil, "null", "null"},
+		{bson.M{"a": 1}, `{"a":1}`, "null"},
+		{bson.M{"$query": bson.M{"a": 1}, "$orderby": bson.M{"b": 1}}, `{"a":1}`, `{"b":1}`},
+		{bson.D{{Name: "a", Value: 1}}, `[{"Name":"a","Value":1}]`, "null"},
+		{bson.D{{Name: "$query", Value: bson.M{"a": 1}}, {Name: "$orderby", Value: bson.M{"b": 1}}}, `{"a":1}`, `{"b":1}`},
+	} {
+		filter, sort, err := legacyQuery(c.query)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if bys, _ := json.Marshal(filter); string(bys) != c.filter {
+			t.Errorf("filter:%s", bys)
+			return
+		}
+		if bys, _ := json.Marshal(sort); string(bys) != c.sort {
+			t.Errorf("sort:%s", bys)
+			return
+		}
+	}
+	//
+	//raw
+	type legacy struct {
+		Query   bson.M `bson:"$query"`
+		Orderby bson.D `bson:"$orderby"`
+	}
+	filter, sort, err := legacyQuery(&legacy{Query: bson.M{"a": 1}, Orderby: bson.D{{Name: "b", Value: 1}, {Name: "c", Value: -1}}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var found = bson.D{}
+	err = sort.(bson.Raw).Unmarshal(&found)
+	if err != nil || len(found) != 2 || found[0].Name != "b" || found[1].Name != "c" || filter == nil {
+		t.Errorf("filter:%v,sort:%v,%v", filter, found, err)
+		return
+	}
+	_, _, err = legacyQuery(TestLegacyQuery)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
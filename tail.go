@@ -0,0 +1,223 @@
+package mongoc
+
+/*
+#include <mongoc.h>
+*/
+import "C"
+import (
+	"context"
+	"time"
+	"unsafe"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//TailOptions is the options for Collection.Tail.
+type TailOptions struct {
+	Context      context.Context //the tailing will be stopped when context is done, default is context.Background().
+	Fields       interface{}     //the projection of document, the ResumeField must be included.
+	ResumeField  string          //the increasing field to re-establish the cursor, default is _id.
+	ResumeAfter  interface{}     //only the document after the value will be returned, it can be nil.
+	MaxAwaitTime time.Duration   //the time to wait for new document on server, default is 1s.
+	BatchSize    int             //the batch size of cursor, zero is server default.
+	RetryDelay   time.Duration   //the delay before re-establish the dead cursor, default is 1s.
+	MaxRetry     int             //the max consecutive failure before stopping, default is 3, negative is retry forever.
+	OplogReplay  bool            //set the oplogReplay flag, it is used for tailing oplog by ts.
+}
+
+//Tail is the long-lived iterator on tailable and awaitData cursor, it is not goroutine safe, following:
+//
+//	tail := col.Tail(bson.M{"level": "error"}, nil)
+//	defer tail.Close()
+//	var log Log
+//	for tail.Next(&log) {
+//		...
+//	}
+//	if err := tail.Err(); err != nil {
+//		...
+//	}
+type Tail struct {
+	C     *Collection
+	Query interface{}
+	Opts  *TailOptions
+	//
+	last   interface{}
+	client *Client
+	cursor *C.mongoc_cursor_t
+	failed int
+	err    error
+	closed bool
+}
+
+//Tail will tail the capped collection by query and options, the opts can be nil.
+//for more https://docs.mongodb.com/manual/core/tailable-cursors/
+func (c *Collection) Tail(query interface{}, opts *TailOptions) *Tail {
+	var tailOpts = TailOptions{}
+	if opts != nil {
+		tailOpts = *opts
+	}
+	if tailOpts.Context == nil {
+		tailOpts.Context = context.Background()
+	}
+	if len(tailOpts.ResumeField) < 1 {
+		tailOpts.ResumeField = "_id"
+	}
+	if tailOpts.MaxAwaitTime <= 0 {
+		tailOpts.MaxAwaitTime = time.Second
+	}
+	if tailOpts.RetryDelay <= 0 {
+		tailOpts.RetryDelay = time.Second
+	}
+	if tailOpts.MaxRetry == 0 {
+		tailOpts.MaxRetry = 3
+	}
+	if query == nil {
+		query = bson.M{}
+	}
+	return &Tail{
+		C:     c,
+		Query: query,
+		Opts:  &tailOpts,
+		last:  tailOpts.ResumeAfter,
+	}
+}
+
+//Last return the resume field value of last returned document.
+func (t *Tail) Last() interface{} {
+	return t.last
+}
+
+//Err return the error which stopped the tailing, it is nil when stopped by context.
+func (t *Tail) Err() error {
+	return t.err
+}
+
+//Next will wait and unmarshal the next document to val, it return false when tailing is stopped.
+//the context is checked between awaiting, so the stopping may be delayed by MaxAwaitTime.
+func (t *Tail) Next(val interface{}) bool {
+	for !t.closed && t.err == nil {
+		if t.Opts.Context.Err() != nil {
+			t.Close()
+			return false
+		}
+		if t.cursor == nil {
+			t.err = t.open()
+			if t.err != nil {
+				return false
+			}
+		}
+		var doc *C.bson_t
+		if C.mongoc_cursor_next(t.cursor, &doc) {
+			var str = C.bson_get_data(doc)
+			mbys := C.GoBytes(unsafe.Pointer(str), C.int(doc.len))
+			t.err = t.unmarshal(mbys, val)
+			t.failed = 0
+			return t.err == nil
+		}
+		var berr C.bson_error_t
+		if C.mongoc_cursor_error(t.cursor, &berr) {
+			err := parseBSONError(&berr)
+			t.client.LastError = err
+			t.failed++
+			if t.Opts.MaxRetry > 0 && t.failed > t.Opts.MaxRetry {
+				t.err = err
+				t.release()
+				return false
+			}
+			warnLog("tail on %v.%v fail with %v, will retry after %v", t.C.DbName, t.C.Name, err, t.Opts.RetryDelay)
+			t.release()
+			t.wait()
+		} else if !C.mongoc_cursor_more(t.cursor) { //the cursor is dead, like empty collection.
+			t.release()
+			t.wait()
+		}
+	}
+	return false
+}
+
+//Close will release the cursor and stop the tailing.
+func (t *Tail) Close() error {
+	t.closed = true
+	t.release()
+	return nil
+}
+
+func (t *Tail) open() (err error) {
+	var query = t.Query
+	if t.last != nil {
+		query = bson.M{
+			"$and": []interface{}{
+				t.Query,
+				bson.M{
+					t.Opts.ResumeField: bson.M{"$gt": t.last},
+				},
+			},
+		}
+	}
+	var opts = bson.M{
+		"tailable":  true,
+		"awaitData": true,
+	}
+	if t.Opts.Fields != nil {
+		opts["projection"] = t.Opts.Fields
+	}
+	if t.Opts.BatchSize > 0 {
+		opts["batchSize"] = t.Opts.BatchSize
+	}
+	if t.Opts.OplogReplay {
+		opts["oplogReplay"] = true
+	}
+	rawQuery, err := parseBSON(query)
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawQuery)
+	rawOpts, err := parseBSON(opts)
+	if err != nil {
+		return
+	}
+	defer C.bson_destroy(rawOpts)
+	t.client = t.C.Pool.Pop()
+	if t.client.session != nil { //the tailing cursor holds the bound client.
+		t.release()
+		err = ErrSessionNotSupported
+		return
+	}
+	var col = t.client.rawCollection(t.C.DbName, t.C.Name)
+	t.cursor = C.mongoc_collection_find_with_opts(col.raw, rawQuery, rawOpts, nil)
+	C.mongoc_cursor_set_max_await_time_ms(t.cursor, C.uint32_t(t.Opts.MaxAwaitTime/time.Millisecond))
+	return
+}
+
+func (t *Tail) unmarshal(mbys []byte, val interface{}) (err error) {
+	var resume = bson.M{}
+	err = bson.Unmarshal(mbys, resume)
+	if err != nil {
+		return
+	}
+	t.last = resume[t.Opts.ResumeField]
+	if val != nil {
+		err = bson.Unmarshal(mbys, val)
+	}
+	return
+}
+
+func (t *Tail) wait() {
+	var timer = time.NewTimer(t.Opts.RetryDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-t.Opts.Context.Done():
+	}
+}
+
+func (t *Tail) release() {
+	if t.cursor != nil {
+		C.mongoc_cursor_destroy(t.cursor)
+		t.cursor = nil
+	}
+	if t.client != nil {
+		t.client.Close()
+		t.client = nil
+	}
+}
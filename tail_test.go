@@ -0,0 +1,99 @@
+package mongoc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestTail(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_capped")
+	col.Drop()
+	err := pool.Execute("test", bson.D{
+		{
+			Name:  "create",
+			Value: "mongoc_capped",
+		},
+		{
+			Name:  "capped",
+			Value: true,
+		},
+		{
+			Name:  "size",
+			Value: 1024 * 1024,
+		},
+	}, nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tail := col.Tail(bson.M{"level": "error"}, &TailOptions{
+		Context:      ctx,
+		MaxAwaitTime: 100 * time.Millisecond,
+		RetryDelay:   100 * time.Millisecond,
+	})
+	go func() {
+		for i := 0; i < 10; i++ {
+			level := "info"
+			if i%2 == 0 {
+				level = "error"
+			}
+			col.Insert(bson.M{"_id": i, "level": level, "msg": fmt.Sprintf("msg-%v", i)})
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+	var received []int
+	var log = bson.M{}
+	for len(received) < 5 && tail.Next(&log) {
+		received = append(received, log["_id"].(int))
+	}
+	if fmt.Sprintf("%v", received) != "[0 2 4 6 8]" || tail.Last() != 8 {
+		t.Errorf("received:%v,last:%v,err:%v", received, tail.Last(), tail.Err())
+		return
+	}
+	//
+	//stop by context
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		cancel()
+	}()
+	if tail.Next(&log) || tail.Err() != nil {
+		t.Errorf("log:%v,err:%v", log, tail.Err())
+		return
+	}
+	//
+	//resume after
+	tail = col.Tail(nil, &TailOptions{
+		ResumeAfter:  6,
+		MaxAwaitTime: 100 * time.Millisecond,
+	})
+	if !tail.Next(&log) || log["_id"] != 7 {
+		t.Errorf("log:%v,err:%v", log, tail.Err())
+		return
+	}
+	tail.Close()
+	if tail.Next(&log) {
+		t.Error("not stopped")
+		return
+	}
+	//
+	//error
+	tail = pool.C("test", "mongoc").Tail(nil, &TailOptions{
+		RetryDelay: 10 * time.Millisecond,
+	})
+	if tail.Next(&log) || tail.Err() == nil {
+		t.Error("not error")
+		return
+	}
+	tail = col.Tail(TestTail, nil)
+	if tail.Next(&log) || tail.Err() == nil {
+		t.Error("not error")
+		return
+	}
+}
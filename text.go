@@ -0,0 +1,110 @@
+package mongoc
+
+import (
+	"fmt"
+	"reflect"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//TextSearchOptions is the options for TextSearch.
+//for more https://docs.mongodb.com/manual/reference/operator/query/text/
+type TextSearchOptions struct {
+	Language           string //the language of terms, the empty is the index default.
+	CaseSensitive      bool
+	DiacriticSensitive bool
+	Filter             interface{} //the additional query, it is combined with $text by $and.
+	Fields             interface{} //the projection, the score field is always added.
+	Sort               []string    //the sort keys by ParseSorted, the $score is sorted by text score, default is $score.
+	Skip               int
+	Limit              int
+	ScoreField         string //the field name of text score, default is score.
+}
+
+//TextSearch will search the document by text index and return the text score of each document, following:
+//
+//	scores, err := col.TextSearch("coffee -shop", &mongoc.TextSearchOptions{Sort: []string{"$score", "-created"}}, &docs)
+//
+//the opts can be nil, the val must be pointer to slice, the scores is in same order of val.
+func (c *Collection) TextSearch(terms string, opts *TextSearchOptions, val interface{}) (scores []float64, err error) {
+	targetVal := reflect.Indirect(reflect.ValueOf(val))
+	if targetVal.Kind() != reflect.Slice {
+		err = fmt.Errorf("the val must be pointer to slice, but %v", reflect.TypeOf(val))
+		return
+	}
+	if opts == nil {
+		opts = &TextSearchOptions{}
+	}
+	var scoreField = opts.ScoreField
+	if len(scoreField) < 1 {
+		scoreField = "score"
+	}
+	var text = bson.D{
+		{
+			Name:  "$search",
+			Value: terms,
+		},
+	}
+	if len(opts.Language) > 0 {
+		text = append(text, bson.DocElem{Name: "$language", Value: opts.Language})
+	}
+	if opts.CaseSensitive {
+		text = append(text, bson.DocElem{Name: "$caseSensitive", Value: true})
+	}
+	if opts.DiacriticSensitive {
+		text = append(text, bson.DocElem{Name: "$diacriticSensitive", Value: true})
+	}
+	var query interface{} = bson.M{"$text": text}
+	if opts.Filter != nil {
+		query = bson.M{"$and": []interface{}{query, opts.Filter}}
+	}
+	var pipeline = NewPipeline().Match(query)
+	if opts.Fields != nil {
+		pipeline.Project(opts.Fields)
+	}
+	pipeline.Stage("$addFields", bson.M{
+		scoreField: bson.M{"$meta": "textScore"},
+	})
+	var sorted = opts.Sort
+	if len(sorted) < 1 {
+		sorted = []string{"$score"}
+	}
+	var sort = bson.D{}
+	for _, key := range sorted {
+		if key == "$score" {
+			sort = append(sort, bson.DocElem{Name: scoreField, Value: -1})
+		} else {
+			sort = append(sort, ParseSorted(key)...)
+		}
+	}
+	pipeline.Stage("$sort", sort)
+	if opts.Skip > 0 {
+		pipeline.Skip(opts.Skip)
+	}
+	if opts.Limit > 0 {
+		pipeline.Limit(opts.Limit)
+	}
+	var raws []bson.Raw
+	err = c.Aggregate(pipeline, nil, &raws)
+	if err != nil {
+		return
+	}
+	newVal := reflect.MakeSlice(targetVal.Type(), 0, len(raws))
+	for _, raw := range raws {
+		var doc bson.M
+		err = raw.Unmarshal(&doc)
+		if err != nil {
+			return
+		}
+		score, _ := doc[scoreField].(float64)
+		scores = append(scores, score)
+		elemVal := reflect.New(targetVal.Type().Elem())
+		err = raw.Unmarshal(elemVal.Interface())
+		if err != nil {
+			return
+		}
+		newVal = reflect.Append(newVal, reflect.Indirect(elemVal))
+	}
+	targetVal.Set(newVal)
+	return
+}
@@ -0,0 +1,82 @@
+package mongoc
+
+import (
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+type textDoc struct {
+	ID    int     `bson:"_id"`
+	Title string  `bson:"title"`
+	Kind  int     `bson:"kind"`
+	Score float64 `bson:"score"`
+}
+
+func TestTextSearch(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_text")
+	col.Drop()
+	err := col.CheckIndex(false, &Index{
+		Name:            "title_text",
+		Key:             []string{"$text:title", "$text:body"},
+		Weights:         map[string]int{"title": 10, "body": 1},
+		DefaultLanguage: "english",
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	col.Insert(
+		bson.M{"_id": 1, "title": "coffee shop", "body": "coffee", "kind": 1},
+		bson.M{"_id": 2, "title": "tea", "body": "coffee and tea", "kind": 2},
+		bson.M{"_id": 3, "title": "Coffee", "body": "", "kind": 2},
+		bson.M{"_id": 4, "title": "café", "body": "", "kind": 1},
+	)
+	var docs []*textDoc
+	scores, err := col.TextSearch("coffee", nil, &docs)
+	if err != nil || len(docs) != 3 || len(scores) != 3 || docs[2].ID != 2 || docs[0].Score != scores[0] || scores[0] < scores[2] {
+		t.Errorf("docs:%v,scores:%v,err:%v", docs, scores, err)
+		return
+	}
+	docs = nil
+	scores, err = col.TextSearch("coffee", &TextSearchOptions{
+		Filter: bson.M{"kind": 2},
+		Fields: bson.M{"title": 1},
+		Sort:   []string{"-_id", "$score"},
+		Limit:  1,
+	}, &docs)
+	if err != nil || len(docs) != 1 || docs[0].ID != 3 || docs[0].Kind != 0 || scores[0] <= 0 {
+		t.Errorf("docs:%v,scores:%v,err:%v", docs, scores, err)
+		return
+	}
+	var raws []bson.M
+	scores, err = col.TextSearch("Coffee", &TextSearchOptions{CaseSensitive: true, ScoreField: "s", Skip: 0}, &raws)
+	if err != nil || len(raws) != 1 || raws[0]["s"] != scores[0] {
+		t.Errorf("docs:%v,scores:%v,err:%v", raws, scores, err)
+		return
+	}
+	docs = nil
+	_, err = col.TextSearch("cafe", &TextSearchOptions{Language: "french", DiacriticSensitive: true}, &docs)
+	if err != nil || len(docs) != 0 {
+		t.Errorf("docs:%v,err:%v", docs, err)
+		return
+	}
+	_, err = col.TextSearch("cafe", nil, &docs)
+	if err != nil || len(docs) != 1 {
+		t.Errorf("docs:%v,err:%v", docs, err)
+		return
+	}
+	//
+	//error
+	_, err = col.TextSearch("coffee", nil, docs)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = col.TextSearch("coffee", &TextSearchOptions{Filter: TestTextSearch}, &docs)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
@@ -0,0 +1,185 @@
+package mongoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//TimeSeries is the time-series options of collection.
+//for more https://docs.mongodb.com/manual/core/timeseries-collections/
+type TimeSeries struct {
+	TimeField   string `bson:"timeField"`
+	MetaField   string `bson:"metaField,omitempty"`
+	Granularity string `bson:"granularity,omitempty"` //seconds/minutes/hours, the empty is server default.
+}
+
+type listCollectionsReply struct {
+	Cursor struct {
+		FirstBatch []struct {
+			Options struct {
+				TimeSeries *TimeSeries `bson:"timeseries"`
+			} `bson:"options"`
+		} `bson:"firstBatch"`
+	} `bson:"cursor"`
+}
+
+//CreateTimeSeries will create the collection as time-series collection, the metaField/granularity can be empty,
+//and the document is expired by timeField when expireAfterSeconds is greater than 0.
+//it return nil when the time-series collection with same timeField is exists.
+func (c *Collection) CreateTimeSeries(timeField, metaField, granularity string, expireAfterSeconds int) (err error) {
+	var options = &TimeSeries{
+		TimeField:   timeField,
+		MetaField:   metaField,
+		Granularity: granularity,
+	}
+	var cmd = bson.D{
+		{
+			Name:  "create",
+			Value: c.Name,
+		},
+		{
+			Name:  "timeseries",
+			Value: options,
+		},
+	}
+	if expireAfterSeconds > 0 {
+		cmd = append(cmd, bson.DocElem{Name: "expireAfterSeconds", Value: expireAfterSeconds})
+	}
+	var client = c.Pool.Pop()
+	err = client.Execute(c.DbName, cmd, nil, &bson.M{})
+	client.Close()
+	if berr, ok := err.(*BSONError); ok && berr.Code == errNamespaceExists {
+		having, terr := c.TimeSeries()
+		if terr != nil {
+			return terr
+		}
+		if having == nil || having.TimeField != timeField || having.MetaField != metaField {
+			return fmt.Errorf("collection(%v.%v) is exists with other options %v", c.DbName, c.Name, having)
+		}
+		err = nil
+	}
+	return
+}
+
+//TimeSeries return the time-series options of collection, it return nil when the collection is not time-series collection.
+//it return ErrNotFound when the collection is not exists.
+func (c *Collection) TimeSeries() (options *TimeSeries, err error) {
+	var client = c.Pool.Pop()
+	defer client.Close()
+	var reply = &listCollectionsReply{}
+	err = client.Execute(c.DbName, bson.D{
+		{
+			Name:  "listCollections",
+			Value: 1,
+		},
+		{
+			Name:  "filter",
+			Value: bson.M{"name": c.Name},
+		},
+	}, nil, reply)
+	if err != nil {
+		return
+	}
+	if len(reply.Cursor.FirstBatch) < 1 {
+		err = ErrNotFound
+		return
+	}
+	options = reply.Cursor.FirstBatch[0].Options.TimeSeries
+	return
+}
+
+//Index will create the secondary index on time-series collection by keys, following:
+//
+//	$time/-$time  the timeField.
+//	$meta/-$meta  the metaField.
+//	xx/-xx        the xx sub field of metaField.
+//
+//the index can be created by CheckIndex/CreateIndexes.
+//it return error when the key is empty or the metaField is empty for $meta/xx key.
+func (t *TimeSeries) Index(name string, keys ...string) (index *Index, err error) {
+	index = &Index{Name: name}
+	for _, key := range keys {
+		var prefix = ""
+		if strings.HasPrefix(key, "-") {
+			prefix, key = "-", key[1:]
+		}
+		switch {
+		case len(key) < 1:
+			err = fmt.Errorf("the index key is empty on %v", keys)
+			return
+		case key == "$time":
+			key = t.TimeField
+		case len(t.MetaField) < 1:
+			err = fmt.Errorf("the metaField is empty for index key(%v)", key)
+			return
+		case key == "$meta":
+			key = t.MetaField
+		default:
+			key = t.MetaField + "." + key
+		}
+		index.Key = append(index.Key, prefix+key)
+	}
+	return
+}
+
+//InsertMeasurements will insert multi measurement document of time-series collection to bulk,
+//the document is grouped by metaField and sorted by timeField, which let the server fill less buckets.
+//the document is marshaled to []byte when it is added, the marshal error will be returned on execute.
+//the document is inserted in order when options is nil.
+func (b *Bulk) InsertMeasurements(options *TimeSeries, docs ...interface{}) {
+	if options == nil {
+		for _, doc := range docs {
+			b.Insert(doc)
+		}
+		return
+	}
+	type measurement struct {
+		doc   interface{}
+		group int
+		time  time.Time
+	}
+	var groups = map[string]int{}
+	var measurements = []*measurement{}
+	for _, doc := range docs {
+		var one = &measurement{doc: doc}
+		measurements = append(measurements, one)
+		bys, err := bson.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var parsed bson.D
+		if bson.Unmarshal(bys, &parsed) != nil {
+			continue
+		}
+		one.doc = bys
+		var meta interface{}
+		for _, elem := range parsed {
+			switch elem.Name {
+			case options.TimeField:
+				one.time, _ = elem.Value.(time.Time)
+			case options.MetaField:
+				meta = elem.Value
+			}
+		}
+		key, _ := bson.Marshal(bson.M{"m": meta})
+		group, ok := groups[string(key)]
+		if !ok {
+			group = len(groups)
+			groups[string(key)] = group
+		}
+		one.group = group
+	}
+	sort.SliceStable(measurements, func(i, j int) bool {
+		if measurements[i].group != measurements[j].group {
+			return measurements[i].group < measurements[j].group
+		}
+		return measurements[i].time.Before(measurements[j].time)
+	})
+	for _, one := range measurements {
+		b.Insert(one.doc)
+	}
+}
@@ -0,0 +1,146 @@
+package mongoc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	bson "gopkg.in/bson.v2"
+)
+
+func TestTimeSeriesIndex(t *testing.T) {
+	options := &TimeSeries{TimeField: "ts", MetaField: "m"}
+	index, err := options.Index("meta_time", "$meta", "-$time")
+	if err != nil || fmt.Sprintf("%v", index.Key) != "[m -ts]" {
+		t.Errorf("index:%v,err:%v", index, err)
+		return
+	}
+	index, err = options.Index("sensor_time", "-sensor", "$time")
+	if err != nil || fmt.Sprintf("%v", index.Key) != "[-m.sensor ts]" {
+		t.Errorf("index:%v,err:%v", index, err)
+		return
+	}
+	_, err = options.Index("empty", "-")
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	//
+	//without metaField
+	options = &TimeSeries{TimeField: "ts"}
+	index, err = options.Index("time", "-$time")
+	if err != nil || fmt.Sprintf("%v", index.Key) != "[-ts]" {
+		t.Errorf("index:%v,err:%v", index, err)
+		return
+	}
+	for _, key := range []string{"$meta", "-sensor"} {
+		_, err = options.Index("meta", key)
+		if err == nil {
+			t.Errorf("%v not error", key)
+			return
+		}
+	}
+}
+
+func TestInsertMeasurements(t *testing.T) {
+	options := &TimeSeries{TimeField: "ts", MetaField: "m"}
+	now := time.Now()
+	bulk := &Bulk{}
+	bulk.InsertMeasurements(options,
+		bson.M{"_id": 1, "ts": now.Add(2 * time.Second), "m": bson.D{{Name: "a", Value: 1}, {Name: "b", Value: 1}}},
+		bson.M{"_id": 2, "ts": now, "m": bson.M{"a": 2}},
+		bson.M{"_id": 3, "ts": now.Add(time.Second), "m": bson.D{{Name: "a", Value: 1}, {Name: "b", Value: 1}}},
+		bson.M{"_id": 4, "ts": now.Add(-time.Second), "m": bson.M{"a": 2}},
+		bson.M{"_id": 5, "ts": now},
+		TestInsertMeasurements,
+	)
+	var ids []interface{}
+	for _, cmd := range bulk.Cmds {
+		var doc bson.M
+		switch v := cmd.(*InsertOneModel).Document.(type) {
+		case []byte:
+			bson.Unmarshal(v, &doc)
+			ids = append(ids, doc["_id"])
+		default:
+			ids = append(ids, "error")
+		}
+	}
+	if fmt.Sprintf("%v", ids) != "[error 3 1 4 2 5]" {
+		t.Error(ids)
+		return
+	}
+	//
+	//nil options
+	bulk = &Bulk{}
+	bulk.InsertMeasurements(nil, bson.M{"_id": 1}, bson.M{"_id": 2})
+	if len(bulk.Cmds) != 2 {
+		t.Error(bulk.Cmds)
+		return
+	}
+}
+
+func TestCreateTimeSeries(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc_timeseries")
+	col.Drop()
+	err := col.CreateTimeSeries("ts", "m", "seconds", 3600)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.CreateTimeSeries("ts", "m", "seconds", 3600)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.CreateTimeSeries("ts2", "m", "seconds", 3600)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	options, err := col.TimeSeries()
+	if err != nil || options.TimeField != "ts" || options.MetaField != "m" || options.Granularity != "seconds" {
+		t.Errorf("options:%v,err:%v", options, err)
+		return
+	}
+	index, err := options.Index("sensor_time", "sensor", "-$time")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = col.CheckIndex(false, index)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	now := time.Now().Truncate(time.Minute)
+	bulk := col.NewBulk(false)
+	for i := 0; i < 10; i++ {
+		bulk.InsertMeasurements(options, bson.M{"ts": now.Add(time.Duration(i) * 30 * time.Second), "m": bson.M{"sensor": i % 2}, "v": i})
+	}
+	_, err = bulk.Execute()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var result []bson.M
+	err = col.Pipe(NewPipeline().Downsample(options, "minute", 1, bson.M{"v": bson.M{"$sum": "$v"}}), &result)
+	if err != nil || len(result) != 10 {
+		t.Errorf("result:%v,err:%v", result, err)
+		return
+	}
+	result = nil
+	err = col.Pipe(NewPipeline().
+		Densify("ts", []string{"m"}, 10, "second", "partition").
+		SetWindowFields("$m", []string{"ts"}, bson.M{"n": bson.M{"$documentNumber": bson.M{}}}), &result)
+	if err != nil || len(result) != 50 {
+		t.Errorf("result:%v,err:%v", len(result), err)
+		return
+	}
+	//
+	_, err = pool.C("test", "mongoc_timeseries_not").TimeSeries()
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+}
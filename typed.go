@@ -0,0 +1,188 @@
+package mongoc
+
+/*
+#include <mongoc.h>
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//FindOptions is the options for TypedCollection.Find.
+//for more http://mongoc.org/libmongoc/current/mongoc_collection_find_with_opts.html
+type FindOptions struct {
+	Projection interface{} //the fields to return.
+	Sort       []string    //the sort keys, see ParseSorted.
+	Skip       int
+	Limit      int
+	BatchSize  int
+}
+
+func (f *FindOptions) bson() bson.M {
+	var opts = bson.M{}
+	if f == nil {
+		return opts
+	}
+	if f.Projection != nil {
+		opts["projection"] = f.Projection
+	}
+	if len(f.Sort) > 0 {
+		opts["sort"] = ParseSorted(f.Sort...)
+	}
+	if f.Skip > 0 {
+		opts["skip"] = f.Skip
+	}
+	if f.Limit > 0 {
+		opts["limit"] = f.Limit
+	}
+	if f.BatchSize > 0 {
+		opts["batchSize"] = f.BatchSize
+	}
+	return opts
+}
+
+//TypedCollection is the generic wrapper of Collection, the T is the document type, it can be struct, pointer to struct or map.
+type TypedCollection[T any] struct {
+	C *Collection
+}
+
+//Typed will create the typed collection.
+func Typed[T any](c *Collection) *TypedCollection[T] {
+	return &TypedCollection[T]{C: c}
+}
+
+//FindOne will find one document by filter, it return ErrNotFound when not found.
+func (t *TypedCollection[T]) FindOne(filter interface{}) (doc T, err error) {
+	err = ErrNotFound
+	var ierr = t.Each(filter, &FindOptions{Limit: 1}, func(val T) error {
+		doc, err = val, nil
+		return nil
+	})
+	if ierr != nil {
+		err = ierr
+	}
+	return
+}
+
+//Find will find all document by filter and options, the opts can be nil.
+func (t *TypedCollection[T]) Find(filter interface{}, opts *FindOptions) (docs []T, err error) {
+	docs = []T{}
+	err = t.Each(filter, opts, func(doc T) error {
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		docs = nil
+	}
+	return
+}
+
+//Insert will insert multi document.
+func (t *TypedCollection[T]) Insert(docs ...T) (err error) {
+	var vals = make([]interface{}, len(docs))
+	for i, doc := range docs {
+		vals[i] = doc
+	}
+	return t.C.Insert(vals...)
+}
+
+//errStopEach is returned by the Each call to stop the iteration without error.
+var errStopEach = fmt.Errorf("stop each")
+
+//Each will find the document by filter and options, and call the call with each document which is read from cursor by batch.
+//the iteration is stopped and the call error is returned when the call return error, the opts can be nil.
+//the client is held until the iteration is done, so the call must not use the pool of Session, it will panic on Pop.
+func (t *TypedCollection[T]) Each(filter interface{}, opts *FindOptions, call func(doc T) error) (err error) {
+	var bound = false
+	if QueryLint() { //run after client is pushed back.
+		defer func() {
+			if err == nil && !bound {
+				var query interface{} = filter
+				if opts != nil && len(opts.Sort) > 0 {
+					query = bson.M{"$query": filter, "$orderby": ParseSorted(opts.Sort...)}
+				}
+				var fields interface{}
+				var skip, limit int
+				if opts != nil {
+					fields, skip, limit = opts.Projection, opts.Skip, opts.Limit
+				}
+				t.C.lintFindAsync(query, fields, skip, limit)
+			}
+		}()
+	}
+	var client = t.C.Pool.Pop()
+	defer client.Close()
+	bound = client.session != nil
+	var col = client.rawCollection(t.C.DbName, t.C.Name)
+	cursor, err := client.findCursor(col, filter, opts.bson())
+	if err != nil {
+		return
+	}
+	defer C.mongoc_cursor_destroy(cursor)
+	var doc *C.bson_t
+	for C.mongoc_cursor_next(cursor, &doc) {
+		var str = C.bson_get_data(doc)
+		mbys := C.GoBytes(unsafe.Pointer(str), C.int(doc.len))
+		var val T
+		val, err = decodeTyped[T](mbys)
+		if err != nil {
+			return
+		}
+		err = call(val)
+		if err != nil {
+			return
+		}
+	}
+	var berr C.bson_error_t
+	if C.mongoc_cursor_error(cursor, &berr) {
+		err = parseBSONError(&berr)
+		client.LastError = err
+	}
+	return
+}
+
+//decodeTyped will unmarshal the bytes to T, the value is allocated when T is pointer.
+func decodeTyped[T any](bys []byte) (val T, err error) {
+	target := reflect.ValueOf(&val).Elem()
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		err = bson.Unmarshal(bys, target.Interface())
+	} else {
+		err = bson.Unmarshal(bys, &val)
+	}
+	return
+}
+
+type distinctValues[K any] struct {
+	Values []K `bson:"values"`
+}
+
+//Distinct will find the distinct values of key by filter, the K is the value type.
+func Distinct[K any](c *Collection, key string, filter interface{}) (values []K, err error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	var reply = &distinctValues[K]{}
+	var client = c.Pool.Pop()
+	defer client.Close()
+	err = client.Execute(c.DbName, bson.D{
+		{
+			Name:  "distinct",
+			Value: c.Name,
+		},
+		{
+			Name:  "key",
+			Value: key,
+		},
+		{
+			Name:  "query",
+			Value: filter,
+		},
+	}, nil, reply)
+	values = reply.Values
+	return
+}
@@ -0,0 +1,30 @@
+//go:build go1.23
+
+package mongoc
+
+import "iter"
+
+//Iter return the iterator of document by filter, the document is read from cursor by batch, following:
+//
+//	for doc, err := range col.Iter(bson.M{"a": 1}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+//the iterator is stopped after the error is yielded, it is required go1.23 or later, see Each for other.
+func (t *TypedCollection[T]) Iter(filter interface{}) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var err = t.Each(filter, nil, func(doc T) error {
+			if !yield(doc, nil) {
+				return errStopEach
+			}
+			return nil
+		})
+		if err != nil && err != errStopEach {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build go1.23
+
+package mongoc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTypedIter(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := Typed[*typedDoc](pool.C("test", "mongoc"))
+	col.C.RemoveAll(nil)
+	for i := 0; i < 10; i++ {
+		col.Insert(&typedDoc{ID: fmt.Sprintf("typed-%v", i), A: i})
+	}
+	var count int
+	for doc, err := range col.Iter(nil) {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if doc == nil || doc.ID == "" {
+			t.Error("doc is empty")
+			return
+		}
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	if count != 5 {
+		t.Errorf("count:%v", count)
+		return
+	}
+	for _, err := range Typed[int](col.C).Iter(nil) {
+		if err == nil {
+			t.Error("not error")
+		}
+		break
+	}
+}
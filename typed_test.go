@@ -0,0 +1,126 @@
+package mongoc
+
+import (
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+type typedDoc struct {
+	ID string `bson:"_id"`
+	A  int    `bson:"a"`
+	B  string `bson:"b"`
+}
+
+func TestTypedCollection(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := Typed[*typedDoc](pool.C("test", "mongoc"))
+	col.C.RemoveAll(nil)
+	var docs []*typedDoc
+	for i := 0; i < 10; i++ {
+		docs = append(docs, &typedDoc{ID: fmt.Sprintf("typed-%v", i), A: i, B: fmt.Sprintf("b%v", i%3)})
+	}
+	err := col.Insert(docs...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//
+	//find
+	doc, err := col.FindOne(bson.M{"a": 3})
+	if err != nil || doc.ID != "typed-3" {
+		t.Errorf("doc:%v,err:%v", doc, err)
+		return
+	}
+	_, err = col.FindOne(bson.M{"a": 100})
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+	found, err := col.Find(bson.M{"a": bson.M{"$gt": 2}}, &FindOptions{
+		Projection: bson.M{"a": 1},
+		Sort:       []string{"-a"},
+		Skip:       1,
+		Limit:      3,
+	})
+	if err != nil || len(found) != 3 || found[0].A != 8 || found[0].B != "" {
+		t.Errorf("found:%v,err:%v", found, err)
+		return
+	}
+	//
+	//each
+	var count int
+	err = col.Each(nil, nil, func(doc *typedDoc) error {
+		if doc == nil || doc.ID == "" {
+			return fmt.Errorf("doc is empty")
+		}
+		count++
+		if count == 5 {
+			return fmt.Errorf("stop")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "stop" || count != 5 {
+		t.Errorf("count:%v,err:%v", count, err)
+		return
+	}
+	values := Typed[bson.M](col.C)
+	vals, err := values.Find(nil, nil)
+	if err != nil || len(vals) != 10 || vals[0]["a"] == nil {
+		t.Errorf("vals:%v,err:%v", vals, err)
+		return
+	}
+	//
+	//distinct
+	keys, err := Distinct[string](col.C, "b", bson.M{"a": bson.M{"$lt": 5}})
+	if err != nil || len(keys) != 3 {
+		t.Errorf("keys:%v,err:%v", keys, err)
+		return
+	}
+	//
+	//error
+	_, err = col.FindOne(TestTypedCollection)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = col.Find(bson.M{"$xx": 1}, nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = Typed[int](col.C).Find(nil, nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+	_, err = Distinct[int](col.C, "b", nil)
+	if err == nil {
+		t.Error("not error")
+		return
+	}
+}
+
+func TestDecodeTyped(t *testing.T) {
+	bys, _ := bson.Marshal(bson.M{"_id": "x", "a": 1})
+	doc, err := decodeTyped[*typedDoc](bys)
+	if err != nil || doc.ID != "x" || doc.A != 1 {
+		t.Errorf("doc:%v,err:%v", doc, err)
+		return
+	}
+	val, err := decodeTyped[typedDoc](bys)
+	if err != nil || val.ID != "x" {
+		t.Errorf("val:%v,err:%v", val, err)
+		return
+	}
+	opts := (&FindOptions{Sort: []string{"-a"}, Limit: 1}).bson()
+	if fmt.Sprintf("%v", opts) != "map[limit:1 sort:[{a -1}]]" {
+		t.Error(opts)
+		return
+	}
+	if len((*FindOptions)(nil).bson()) != 0 {
+		t.Error("error")
+		return
+	}
+}
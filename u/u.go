@@ -0,0 +1,244 @@
+//Package u is the fluent update builder, following:
+//
+//	col.UpdateOne(q.Eq("_id", id), u.Set("a", 1).Inc("b", 2).Push("c", 3), nil)
+//
+//the update is marshaled to bson.D by GetBSON, so it can be used anywhere the update document is accepted,
+//and the invalid usage like conflict path is returned as error on marshaling.
+package u
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	bson "gopkg.in/bson.v2"
+	mongoc "gopkg.in/mongoc.v1"
+)
+
+//Update is the update builder.
+type Update struct {
+	doc   bson.D
+	ops   map[string]int //operator to doc index.
+	paths []string
+	typ   reflect.Type
+	err   error
+}
+
+//New will create the empty update.
+func New() *Update {
+	return &Update{
+		doc: bson.D{},
+		ops: map[string]int{},
+	}
+}
+
+//For will create the empty update on struct type of v, the field name is go field path like Address.City,
+//and it will be mapped to bson name by bson tag.
+func For(v interface{}) *Update {
+	update := New()
+	update.typ = reflect.TypeOf(v)
+	return update
+}
+
+//Err return the first error of building.
+func (u *Update) Err() error {
+	if u.err == nil && len(u.doc) < 1 {
+		return fmt.Errorf("update is empty")
+	}
+	return u.err
+}
+
+//D return the update document, it will panic when building fail.
+func (u *Update) D() bson.D {
+	if err := u.Err(); err != nil {
+		panic(err)
+	}
+	return u.doc
+}
+
+//GetBSON is the bson.Getter impl.
+func (u *Update) GetBSON() (interface{}, error) {
+	return u.doc, u.Err()
+}
+
+func (u *Update) fail(format string, args ...interface{}) *Update {
+	if u.err == nil {
+		u.err = fmt.Errorf(format, args...)
+	}
+	return u
+}
+
+//Op append {op:{field:value}}, it fail when the field path conflict with other field.
+func (u *Update) Op(op, field string, value interface{}) *Update {
+	if !updateOps[op] {
+		return u.fail("invalid update operator(%v)", op)
+	}
+	if len(field) < 1 || strings.TrimSpace(field) != field || strings.HasPrefix(field, "$") {
+		return u.fail("invalid field name(%v)", field)
+	}
+	var name = field
+	if u.typ != nil {
+		var err error
+		name, err = mongoc.FieldPath(u.typ, field)
+		if err != nil {
+			return u.fail("%v", err)
+		}
+	}
+	for _, path := range u.paths {
+		if path == name || strings.HasPrefix(path, name+".") || strings.HasPrefix(name, path+".") {
+			return u.fail("updating the path(%v) would create a conflict at %v", name, path)
+		}
+	}
+	u.paths = append(u.paths, name)
+	index, having := u.ops[op]
+	if !having {
+		index = len(u.doc)
+		u.ops[op] = index
+		u.doc = append(u.doc, bson.DocElem{Name: op, Value: bson.D{}})
+	}
+	u.doc[index].Value = append(u.doc[index].Value.(bson.D), bson.DocElem{Name: name, Value: value})
+	return u
+}
+
+var updateOps = map[string]bool{
+	"$set": true, "$setOnInsert": true, "$unset": true, "$inc": true, "$mul": true,
+	"$min": true, "$max": true, "$rename": true, "$currentDate": true,
+	"$push": true, "$addToSet": true, "$pull": true, "$pullAll": true, "$pop": true,
+}
+
+//Set append {$set:{field:value}}.
+func (u *Update) Set(field string, value interface{}) *Update {
+	return u.Op("$set", field, value)
+}
+
+//SetOnInsert append {$setOnInsert:{field:value}}.
+func (u *Update) SetOnInsert(field string, value interface{}) *Update {
+	return u.Op("$setOnInsert", field, value)
+}
+
+//Unset append {$unset:{field:""}}.
+func (u *Update) Unset(field string) *Update {
+	return u.Op("$unset", field, "")
+}
+
+//Inc append {$inc:{field:value}}, the value must be number.
+func (u *Update) Inc(field string, value interface{}) *Update {
+	if !isNumber(value) {
+		return u.fail("the $inc value of field(%v) must be number, but %v", field, reflect.TypeOf(value))
+	}
+	return u.Op("$inc", field, value)
+}
+
+//Mul append {$mul:{field:value}}, the value must be number.
+func (u *Update) Mul(field string, value interface{}) *Update {
+	if !isNumber(value) {
+		return u.fail("the $mul value of field(%v) must be number, but %v", field, reflect.TypeOf(value))
+	}
+	return u.Op("$mul", field, value)
+}
+
+//Min append {$min:{field:value}}.
+func (u *Update) Min(field string, value interface{}) *Update {
+	return u.Op("$min", field, value)
+}
+
+//Max append {$max:{field:value}}.
+func (u *Update) Max(field string, value interface{}) *Update {
+	return u.Op("$max", field, value)
+}
+
+//Rename append {$rename:{field:newName}}.
+func (u *Update) Rename(field, newName string) *Update {
+	return u.Op("$rename", field, newName)
+}
+
+//CurrentDate append {$currentDate:{field:true}}.
+func (u *Update) CurrentDate(field string) *Update {
+	return u.Op("$currentDate", field, true)
+}
+
+//Push append {$push:{field:value}}.
+func (u *Update) Push(field string, value interface{}) *Update {
+	return u.Op("$push", field, value)
+}
+
+//PushEach append {$push:{field:{$each:values}}}, the values must be slice or array.
+func (u *Update) PushEach(field string, values interface{}) *Update {
+	if !isList(values) {
+		return u.fail("the $each value of field(%v) must be slice, but %v", field, reflect.TypeOf(values))
+	}
+	return u.Op("$push", field, bson.M{"$each": values})
+}
+
+//AddToSet append {$addToSet:{field:value}}.
+func (u *Update) AddToSet(field string, value interface{}) *Update {
+	return u.Op("$addToSet", field, value)
+}
+
+//Pull append {$pull:{field:condition}}.
+func (u *Update) Pull(field string, condition interface{}) *Update {
+	return u.Op("$pull", field, condition)
+}
+
+//Pop append {$pop:{field:1}} to remove the last element, or {$pop:{field:-1}} to remove the first when first is true.
+func (u *Update) Pop(field string, first bool) *Update {
+	if first {
+		return u.Op("$pop", field, -1)
+	}
+	return u.Op("$pop", field, 1)
+}
+
+func isNumber(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isList(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	kind := reflect.TypeOf(v).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+//Set create update with {$set:{field:value}}.
+func Set(field string, value interface{}) *Update {
+	return New().Set(field, value)
+}
+
+//SetOnInsert create update with {$setOnInsert:{field:value}}.
+func SetOnInsert(field string, value interface{}) *Update {
+	return New().SetOnInsert(field, value)
+}
+
+//Unset create update with {$unset:{field:""}}.
+func Unset(field string) *Update {
+	return New().Unset(field)
+}
+
+//Inc create update with {$inc:{field:value}}.
+func Inc(field string, value interface{}) *Update {
+	return New().Inc(field, value)
+}
+
+//Push create update with {$push:{field:value}}.
+func Push(field string, value interface{}) *Update {
+	return New().Push(field, value)
+}
+
+//AddToSet create update with {$addToSet:{field:value}}.
+func AddToSet(field string, value interface{}) *Update {
+	return New().AddToSet(field, value)
+}
+
+//Pull create update with {$pull:{field:condition}}.
+func Pull(field string, condition interface{}) *Update {
+	return New().Pull(field, condition)
+}
@@ -0,0 +1,85 @@
+package u
+
+import (
+	"fmt"
+	"testing"
+
+	bson "gopkg.in/bson.v2"
+)
+
+type address struct {
+	City string `bson:"city"`
+}
+
+type user struct {
+	Name    string   `bson:"n"`
+	Count   int      `bson:"count"`
+	Address *address `bson:"addr"`
+	Tags    []string `bson:"tags"`
+}
+
+func TestUpdate(t *testing.T) {
+	update := Set("a", 1).Inc("b", 2).Set("c.d", 3).Push("e", 4).PushEach("f", []int{1, 2}).Unset("g").Pop("h", true)
+	bys, err := bson.Marshal(update)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var doc bson.D
+	bson.Unmarshal(bys, &doc)
+	if fmt.Sprintf("%v", doc) != "[{$set [{a 1} {c.d 3}]} {$inc [{b 2}]} {$push [{e 4} {f [{$each [1 2]}]}]} {$unset [{g }]} {$pop [{h -1}]}]" {
+		t.Error(doc)
+		return
+	}
+	update = New().SetOnInsert("a", 1).Mul("b", 1.5).Min("c", 1).Max("d", 2).Rename("e", "ee").CurrentDate("f").AddToSet("g", 1).Pull("h", 1).Pop("i", false)
+	if len(update.D()) != 9 {
+		t.Error(update.D())
+		return
+	}
+	//
+	//struct tag
+	update = For(&user{}).Set("Name", "x").Inc("Count", 1).Set("Address.City", "c").Push("Tags", "t")
+	if fmt.Sprintf("%v", update.D()) != "[{$set [{n x} {addr.city c}]} {$inc [{count 1}]} {$push [{tags t}]}]" {
+		t.Error(update.D())
+		return
+	}
+	//
+	//error
+	for i, update := range []*Update{
+		New(),
+		Set("$a", 1),
+		Set("a ", 1),
+		Set("a", 1).Set("a", 2),
+		Set("a", 1).Inc("a", 2),
+		Set("a", 1).Unset("a.b"),
+		Set("a.b", 1).Unset("a"),
+		Inc("a", "x"),
+		New().Mul("a", nil),
+		New().PushEach("a", 1),
+		New().Op("$sets", "a", 1),
+		For(user{}).Set("Nick", 1),
+	} {
+		if update.Err() == nil {
+			t.Errorf("%v not error", i)
+			return
+		}
+		if _, err := bson.Marshal(bson.M{"u": update}); err == nil {
+			t.Errorf("%v not error", i)
+			return
+		}
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("not panic")
+			}
+		}()
+		New().D()
+	}()
+	//
+	//no conflict with same prefix
+	if Set("a", 1).Set("ab", 2).Err() != nil {
+		t.Error("error")
+		return
+	}
+}
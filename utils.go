@@ -4,10 +4,18 @@ import bson "gopkg.in/bson.v2"
 import "strings"
 
 //ParseSorted will parse sorted string to bson.D, following by:
-//-xx to xx:-1; xx to xx:1
+//-xx to xx:-1; xx to xx:1; $type:xx to xx:type
+//
+//the $type:xx is the special index key, like $2dsphere:loc, $2d:loc, $text:content, $hashed:_id.
 func ParseSorted(sort ...string) (doc bson.D) {
 	for _, s := range sort {
-		if strings.HasPrefix(s, "-") {
+		if strings.HasPrefix(s, "$") && strings.Contains(s, ":") {
+			typ, name, _ := strings.Cut(strings.TrimPrefix(s, "$"), ":")
+			doc = append(doc, bson.DocElem{
+				Name:  name,
+				Value: typ,
+			})
+		} else if strings.HasPrefix(s, "-") {
 			doc = append(doc, bson.DocElem{
 				Name:  strings.TrimPrefix(s, "-"),
 				Value: -1,
@@ -23,14 +31,27 @@ func ParseSorted(sort ...string) (doc bson.D) {
 }
 
 //ParseDoc will parse doc to sort string, following by:
-//doc.Value<0 to -xx; doc.Value>0 to xx:1
+//doc.Value<0 to -xx; doc.Value>0 to xx:1; doc.Value is string to $type:xx
 func ParseDoc(doc bson.D) (keys []string) {
 	for _, d := range doc {
-		val := d.Value.(int)
-		if val > 0 {
-			keys = append(keys, d.Name)
-		} else {
+		var val float64
+		switch v := d.Value.(type) {
+		case string:
+			keys = append(keys, "$"+v+":"+d.Name)
+			continue
+		case int:
+			val = float64(v)
+		case int32:
+			val = float64(v)
+		case int64:
+			val = float64(v)
+		case float64:
+			val = v
+		}
+		if val < 0 {
 			keys = append(keys, "-"+d.Name)
+		} else {
+			keys = append(keys, d.Name)
 		}
 	}
 	return
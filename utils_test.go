@@ -30,4 +30,33 @@ func TestSorted(t *testing.T) {
 		t.Error("parse error")
 		return
 	}
+	//
+	//special key
+	doc = ParseSorted("$2dsphere:loc", "-a", "$text:content", "$hashed:_id")
+	if len(doc) != 4 || doc[0].Name != "loc" || doc[0].Value != "2dsphere" || doc[2].Value != "text" || doc[3].Name != "_id" {
+		t.Errorf("parse error:%v", doc)
+		return
+	}
+	sorted = ParseDoc(bson.D{
+		{
+			Name:  "loc",
+			Value: "2dsphere",
+		},
+		{
+			Name:  "a",
+			Value: -1.0,
+		},
+		{
+			Name:  "b",
+			Value: int64(1),
+		},
+		{
+			Name:  "c",
+			Value: int32(-1),
+		},
+	})
+	if len(sorted) != 4 || sorted[0] != "$2dsphere:loc" || sorted[1] != "-a" || sorted[2] != "b" || sorted[3] != "-c" {
+		t.Errorf("parse error:%v", sorted)
+		return
+	}
 }
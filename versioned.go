@@ -0,0 +1,106 @@
+package mongoc
+
+import (
+	"fmt"
+	"reflect"
+
+	bson "gopkg.in/bson.v2"
+)
+
+//ErrVersionConflict is the error when the document is modified by other after reading.
+var ErrVersionConflict = fmt.Errorf("version conflict")
+
+//UpdateVersioned will update the document by {_id,version} selector and increase the version, following:
+//
+//	type Order struct {
+//		ID      string `bson:"_id"`
+//		Status  int    `bson:"status"`
+//		Version int    `bson:"version" mongoc:"version"`
+//	}
+//
+//the doc must be pointer to struct with _id field and integer version field tagged by mongoc:"version",
+//all other fields are updated by $set, and the version of doc is increased after updated.
+//the document without version field is matched as version 0.
+//it return ErrVersionConflict when the document is modified by other or not found.
+func (c *Collection) UpdateVersioned(doc interface{}) (err error) {
+	var val = reflect.ValueOf(doc)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		err = fmt.Errorf("the doc must be pointer to struct, but %v", val.Type())
+		return
+	}
+	model, err := ParseModel(val.Elem().Type())
+	if err != nil {
+		return
+	}
+	id, ok := model.field(val, model.id)
+	if !ok {
+		err = fmt.Errorf("the _id field is not found on %v", model.Type)
+		return
+	}
+	version, ok := model.field(val, model.version)
+	if !ok {
+		err = fmt.Errorf("the version field is not found on %v", model.Type)
+		return
+	}
+	switch version.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		err = fmt.Errorf("the version field must be integer, but %v", version.Type())
+		return
+	}
+	bys, err := bson.Marshal(doc)
+	if err != nil {
+		return
+	}
+	var set = bson.M{}
+	err = bson.Unmarshal(bys, set)
+	if err != nil {
+		return
+	}
+	delete(set, "_id")
+	delete(set, model.versionName)
+	var update = bson.M{
+		"$inc": bson.M{
+			model.versionName: 1,
+		},
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	var expected interface{} = version.Interface()
+	if version.Int() == 0 { //the document without version field is treated as version 0.
+		expected = bson.M{"$in": []interface{}{0, nil}}
+	}
+	_, err = c.UpdateOne(bson.M{
+		"_id":             id.Interface(),
+		model.versionName: expected,
+	}, update, nil)
+	if err == ErrNotFound {
+		err = ErrVersionConflict
+	}
+	if err == nil {
+		version.SetInt(version.Int() + 1)
+	}
+	return
+}
+
+//UpdateVersionedRetry will read the document by id to doc, apply the mutate and update it by UpdateVersioned,
+//it will retry by reading again when version conflict, and return ErrVersionConflict when all retry fail.
+//the mutate error will stop the retry and be returned.
+func (c *Collection) UpdateVersionedRetry(id, doc interface{}, retry int, mutate func() error) (err error) {
+	for i := 0; i <= retry; i++ {
+		err = c.FindOne(bson.M{"_id": id}, nil, doc)
+		if err != nil {
+			return
+		}
+		err = mutate()
+		if err != nil {
+			return
+		}
+		err = c.UpdateVersioned(doc)
+		if err != ErrVersionConflict {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,115 @@
+package mongoc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type versionedDoc struct {
+	ID      string `bson:"_id"`
+	Count   int    `bson:"count"`
+	Version int64  `bson:"v" mongoc:"version"`
+}
+
+func TestUpdateVersioned(t *testing.T) {
+	pool := NewPool("mongodb://loc.m:27017", 100, 1)
+	col := pool.C("test", "mongoc")
+	col.RemoveAll(nil)
+	doc := &versionedDoc{ID: "versioned"}
+	err := col.Insert(doc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	stale := *doc
+	doc.Count = 1
+	err = col.UpdateVersioned(doc)
+	if err != nil || doc.Version != 1 {
+		t.Errorf("doc:%v,err:%v", doc, err)
+		return
+	}
+	stale.Count = 2
+	err = col.UpdateVersioned(&stale)
+	if err != ErrVersionConflict {
+		t.Error(err)
+		return
+	}
+	//
+	//missing version
+	_, err = col.Upsert(map[string]interface{}{"_id": "unversioned"}, map[string]interface{}{"$set": map[string]interface{}{"count": 1}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	legacy := &versionedDoc{ID: "unversioned", Count: 2}
+	err = col.UpdateVersioned(legacy)
+	if err != nil || legacy.Version != 1 {
+		t.Errorf("legacy:%v,err:%v", legacy, err)
+		return
+	}
+	//
+	//retry
+	var waiter sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		waiter.Add(1)
+		go func() {
+			defer waiter.Done()
+			doc := &versionedDoc{}
+			err := col.UpdateVersionedRetry("versioned", doc, 100, func() error {
+				doc.Count++
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	waiter.Wait()
+	found := &versionedDoc{}
+	err = col.FindOne(map[string]interface{}{"_id": "versioned"}, nil, found)
+	if err != nil || found.Count != 6 || found.Version != 6 {
+		t.Errorf("found:%v,err:%v", found, err)
+		return
+	}
+	err = col.UpdateVersionedRetry("versioned", found, 1, func() error {
+		return fmt.Errorf("stop")
+	})
+	if err == nil || err.Error() != "stop" {
+		t.Error(err)
+		return
+	}
+	err = col.UpdateVersionedRetry("none", found, 1, func() error {
+		return nil
+	})
+	if err != ErrNotFound {
+		t.Error(err)
+		return
+	}
+}
+
+func TestUpdateVersionedError(t *testing.T) {
+	col := &Collection{}
+	for _, doc := range []interface{}{
+		versionedDoc{},
+		&struct {
+			Version int `mongoc:"version"`
+		}{},
+		&struct {
+			ID string `bson:"_id"`
+		}{},
+		&struct {
+			ID      string `bson:"_id"`
+			Version string `mongoc:"version"`
+		}{},
+		&struct {
+			ID      string `bson:"_id"`
+			Version int    `mongoc:"xx"`
+		}{},
+	} {
+		if col.UpdateVersioned(doc) == nil {
+			t.Errorf("not error on %v", doc)
+			return
+		}
+	}
+}